@@ -0,0 +1,59 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrAlreadySent is returned when a Request that has already been sent
+// is sent again.
+var ErrAlreadySent = errors.New("request already sent")
+
+// Result is the outcome of a request sent with SendAsync.
+type Result struct {
+	// Response is nil if Err is not nil.
+	Response *Response
+	Err      error
+}
+
+// SendAsync sends the request like Send but returns immediately. The
+// outcome is delivered on the returned channel, so callers can select on
+// it alongside other channels instead of dedicating a goroutine to a
+// blocking Send.
+//
+// An error is returned if the Request has already been sent.
+func (r *Request) SendAsync(ctx context.Context, data interface{}) (<-chan Result, error) {
+	if r.alreadySent {
+		return nil, ErrAlreadySent
+	}
+	r.alreadySent = true
+
+	ch := make(chan Result, 1) // Buffered so that an abandoned result doesn't leak the goroutine
+	go func() {
+		err := r.send(ctx, data)
+		if err != nil {
+			ch <- Result{Err: err}
+			return
+		}
+		ch <- Result{Response: newResponse(r)}
+	}()
+	return ch, nil
+}
+
+// SendWithCallback sends the request like Send but returns immediately
+// and calls cb with the outcome. It suits UI code driven by event
+// callbacks, such as React event handlers, which must not block.
+//
+// cb is called from its own goroutine, so it may block. If the Request
+// has already been sent, cb is called with ErrAlreadySent.
+func (r *Request) SendWithCallback(ctx context.Context, data interface{}, cb func(resp *Response, err error)) {
+	ch, err := r.SendAsync(ctx, data)
+	go func() {
+		if err != nil {
+			cb(nil, err)
+			return
+		}
+		res := <-ch
+		cb(res.Response, res.Err)
+	}()
+}
@@ -0,0 +1,125 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// ErrNoBlob is returned by ResponseBlob when the response is not a Blob.
+var ErrNoBlob = errors.New("response is not a blob")
+
+// BlobObject wraps JavaScript Blob objects, such as responses of requests
+// with ResponseType Blob, and File objects.
+type BlobObject struct {
+	*js.Object
+	Size int64  `js:"size"`
+	Type string `js:"type"`
+
+	objectURL string
+}
+
+// WrapBlob wraps the Blob o.
+func WrapBlob(o *js.Object) *BlobObject {
+	return &BlobObject{Object: o}
+}
+
+// ResponseBlob returns the response as a BlobObject. The request's
+// ResponseType must be Blob.
+func (r *Request) ResponseBlob() (*BlobObject, error) {
+	if r.ResponseType != Blob || r.Response == nil {
+		return nil, ErrNoBlob
+	}
+	return WrapBlob(r.Response), nil
+}
+
+// Bytes reads the contents of the blob. Blobs are read asynchronously
+// by the browser, so Bytes blocks until the read has completed.
+func (b *BlobObject) Bytes(ctx context.Context) ([]byte, error) {
+	fr := js.Global.Get("FileReader").New()
+	done := make(chan error, 1) // Buffered so that the listener never blocks
+	fr.Set("onload", func(*js.Object) {
+		done <- nil
+	})
+	fr.Set("onerror", func(*js.Object) {
+		done <- errors.New(fr.Get("error").Get("message").String())
+	})
+	fr.Call("readAsArrayBuffer", b.Object)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, err
+		}
+		return js.Global.Get("Uint8Array").New(fr.Get("result")).Interface().([]byte), nil
+	case <-ctx.Done():
+		fr.Call("abort")
+		return nil, ctx.Err()
+	}
+}
+
+// ObjectURL returns an object URL for the blob, which can be used as the
+// src of an <img> or the href of an <a download>. The URL is created on
+// the first call and kept alive until RevokeObjectURL is called.
+func (b *BlobObject) ObjectURL() string {
+	if b.objectURL == "" {
+		b.objectURL = js.Global.Get("URL").Call("createObjectURL", b.Object).String()
+	}
+	return b.objectURL
+}
+
+// RevokeObjectURL releases the object URL returned by ObjectURL, so that
+// the browser can free the blob's memory.
+func (b *BlobObject) RevokeObjectURL() {
+	if b.objectURL != "" {
+		js.Global.Get("URL").Call("revokeObjectURL", b.objectURL)
+		b.objectURL = ""
+	}
+}
+
+// FileObject wraps JavaScript File objects, such as those picked with an
+// <input type=file> element.
+type FileObject struct {
+	*BlobObject
+}
+
+// WrapFile wraps the File o.
+func WrapFile(o *js.Object) *FileObject {
+	return &FileObject{BlobObject: WrapBlob(o)}
+}
+
+// Name returns the file's name, without a path.
+func (f *FileObject) Name() string {
+	return f.Get("name").String()
+}
+
+// LastModified returns the time the file was last modified.
+func (f *FileObject) LastModified() time.Time {
+	return time.UnixMilli(f.Get("lastModified").Int64())
+}
+
+// SendBlob sends the request with b as its body. The "Content-Type" is
+// set to the blob's type, unless it is empty or a "Content-Type" has
+// been set already. If onProgress is not nil, it is called as the body is
+// uploaded, from an event listener, so it must not block. The listener
+// is removed when SendBlob returns.
+//
+// Like Send, SendBlob panics if the request has already been sent,
+// unless StrictErrors is set.
+func (r *Request) SendBlob(ctx context.Context, b *BlobObject, onProgress func(ProgressEvent)) error {
+	if b.Type != "" && r.header.Get("Content-Type") == "" {
+		r.SetRequestHeader("Content-Type", b.Type)
+	}
+	if onProgress != nil {
+		l := r.Upload().OnProgress(onProgress)
+		defer l.Remove()
+	}
+	return r.Send(ctx, b.Object)
+}
+
+// SendFile is like SendBlob for files.
+func (r *Request) SendFile(ctx context.Context, f *FileObject, onProgress func(ProgressEvent)) error {
+	return r.SendBlob(ctx, f.BlobObject, onProgress)
+}
@@ -0,0 +1,173 @@
+package xhr
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// readerChunkSize is the size of the chunks read from io.Reader request
+// bodies.
+const readerChunkSize = 64 << 10
+
+// readerBlob reads rd in chunks into a Blob. Only the chunks are held in
+// Go memory while reading, never the complete body as a single []byte.
+func readerBlob(rd io.Reader) (*js.Object, error) {
+	parts := js.Global.Get("Array").New()
+	for {
+		buf := make([]byte, readerChunkSize)
+		n, err := rd.Read(buf)
+		if n > 0 {
+			parts.Call("push", js.Global.Get("Blob").New([]interface{}{buf[:n]}))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return js.Global.Get("Blob").New(parts), nil
+}
+
+var (
+	requestStreamsOnce sync.Once
+	requestStreams     bool
+)
+
+// supportsRequestStreams reports whether the browser's fetch accepts
+// ReadableStream request bodies.
+func supportsRequestStreams() bool {
+	requestStreamsOnce.Do(func() {
+		if js.Global.Get("ReadableStream") == js.Undefined || js.Global.Get("Request") == js.Undefined {
+			return
+		}
+		// Browsers without support ignore duplex and send the stream as
+		// the string "[object ReadableStream]", adding a Content-Type.
+		duplexAccessed := false
+		opts := js.Global.Get("Object").New()
+		opts.Set("method", "POST")
+		opts.Set("body", js.Global.Get("ReadableStream").New())
+		js.Global.Get("Object").Call("defineProperty", opts, "duplex", js.M{
+			"get": func() string {
+				duplexAccessed = true
+				return "half"
+			},
+		})
+		var hasContentType bool
+		err := catch(func() {
+			req := js.Global.Get("Request").New(locationHref(), opts)
+			hasContentType = req.Get("headers").Call("has", "Content-Type").Bool()
+		})
+		requestStreams = err == nil && duplexAccessed && !hasContentType
+	})
+	return requestStreams
+}
+
+// StreamUpload sends body to url as it is read, using a fetch request
+// with a streaming body where the browser supports it, so that large
+// generated payloads are never held in memory. Elsewhere, body is read
+// into a Blob and sent with XMLHttpRequest. Streaming request bodies
+// require HTTP/2 or later.
+//
+// Like Do, status codes 4xx and 5xx are only treated as errors if
+// FailOnError is set. The Request field of the Response is nil when the
+// body was streamed.
+func StreamUpload(ctx context.Context, method, url, contentType string, body io.Reader) (*Response, error) {
+	if !supportsRequestStreams() {
+		req := NewRequest(method, url)
+		req.ResponseType = ArrayBuffer
+		req.FailOnError = FailOnError
+		if contentType != "" {
+			req.SetRequestHeader("Content-Type", contentType)
+		}
+		if err := req.Send(ctx, body); err != nil {
+			return nil, err
+		}
+		return newResponse(req), nil
+	}
+
+	ctrl := js.Global.Get("AbortController").New()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ctrl.Call("abort")
+		case <-stop:
+		}
+	}()
+
+	stream := js.Global.Get("ReadableStream").New(js.M{
+		"pull": func(c *js.Object) *js.Object {
+			return js.Global.Get("Promise").New(func(resolve, reject *js.Object) {
+				go func() { // Read may block, which isn't permitted in callbacks
+					buf := make([]byte, readerChunkSize)
+					n, err := body.Read(buf)
+					if n > 0 {
+						c.Call("enqueue", buf[:n])
+					}
+					switch {
+					case err == io.EOF:
+						c.Call("close")
+					case err != nil:
+						reject.Invoke(js.Global.Get("Error").New(err.Error()))
+						return
+					}
+					resolve.Invoke()
+				}()
+			})
+		},
+	})
+
+	headers := js.M{}
+	if contentType != "" {
+		headers["Content-Type"] = contentType
+	}
+	start := time.Now()
+	resp, err := await(js.Global.Call("fetch", url, js.M{
+		"method":  method,
+		"body":    stream,
+		"duplex":  "half",
+		"headers": headers,
+		"signal":  ctrl.Get("signal"),
+	}))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, ErrFailure
+	}
+	buf, err := await(resp.Call("arrayBuffer"))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, ErrFailure
+	}
+
+	header := http.Header{}
+	resp.Get("headers").Call("forEach", func(value, name string) {
+		header.Add(name, value)
+	})
+	r := &Response{
+		Status:     resp.Get("status").Int(),
+		StatusText: resp.Get("statusText").String(),
+		Header:     header,
+		Body:       js.Global.Get("Uint8Array").New(buf).Interface().([]byte),
+		URL:        resp.Get("url").String(),
+		Duration:   time.Since(start),
+	}
+	if FailOnError && !resp.Get("ok").Bool() {
+		b := r.Body
+		if len(b) > maxErrorBody {
+			b = b[:maxErrorBody]
+		}
+		return nil, &StatusError{code: r.Status, text: r.StatusText, header: header, body: b}
+	}
+	return r, nil
+}
@@ -0,0 +1,67 @@
+//go:build js
+
+package xhr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// downloadSize is the size of the response used by
+// BenchmarkResponseBytes.
+const downloadSize = 4 << 20
+
+func fetchBody(b *testing.B, responseType string) *Request {
+	req := NewRequest("GET", testServer(b)+"/bytes?n=4194304")
+	req.ResponseType = responseType
+	if err := req.Send(context.Background(), nil); err != nil {
+		b.Fatal(err)
+	}
+	return req
+}
+
+func BenchmarkResponseBytes(b *testing.B) {
+	b.Run("Text", func(b *testing.B) {
+		req := fetchBody(b, Text)
+		b.SetBytes(downloadSize)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = []byte(req.ResponseText)
+		}
+	})
+
+	b.Run("Uint8ArrayInterface", func(b *testing.B) {
+		req := fetchBody(b, ArrayBuffer)
+		b.SetBytes(downloadSize)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_ = js.Global.Get("Uint8Array").New(req.Response).Interface().([]byte)
+		}
+	})
+
+	b.Run("ArrayBufferCopy", func(b *testing.B) {
+		req := fetchBody(b, ArrayBuffer)
+		b.SetBytes(downloadSize)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			req.bodyView = nil
+			_ = req.ResponseArrayBufferBytes(false)
+		}
+	})
+
+	b.Run("ArrayBufferShared", func(b *testing.B) {
+		req := fetchBody(b, ArrayBuffer)
+		b.SetBytes(downloadSize)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			req.bodyView = nil
+			_ = req.ResponseArrayBufferBytes(true)
+		}
+	})
+}
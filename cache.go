@@ -0,0 +1,94 @@
+package xhr
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachePolicy holds the caching directives of a response.
+type CachePolicy struct {
+	// MaxAge is the freshness lifetime given by the max-age directive,
+	// or derived from Expires. It is -1 if neither is given.
+	MaxAge time.Duration
+
+	// Age is the value of the Age header.
+	Age time.Duration
+
+	NoStore        bool
+	NoCache        bool
+	MustRevalidate bool
+	Private        bool
+	Immutable      bool
+
+	// FreshUntil is the time until which the response may be served
+	// from a cache without revalidation. It is the zero Time if the
+	// response must not be cached without revalidation.
+	FreshUntil time.Time
+}
+
+// Fresh reports whether the response may still be served from a cache
+// without revalidation.
+func (p CachePolicy) Fresh() bool {
+	return time.Now().Before(p.FreshUntil)
+}
+
+// ResponseCachePolicy parses the Cache-Control, Expires, Age and Date
+// headers of the response, so that application-level caches can honor
+// the server's directives.
+func (r *Request) ResponseCachePolicy() CachePolicy {
+	return parseCachePolicy(r.ResponseHeaderMap(), time.Now())
+}
+
+// parseCachePolicy parses the caching headers of h for a response
+// received at now.
+func parseCachePolicy(h http.Header, now time.Time) CachePolicy {
+	p := CachePolicy{MaxAge: -1}
+
+	for _, directive := range strings.Split(strings.Join(h.Values("Cache-Control"), ","), ",") {
+		name, value := strings.TrimSpace(directive), ""
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			name, value = name[:i], strings.Trim(name[i+1:], `"`)
+		}
+		switch strings.ToLower(name) {
+		case "max-age":
+			if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+				p.MaxAge = time.Duration(secs) * time.Second
+			}
+		case "no-store":
+			p.NoStore = true
+		case "no-cache":
+			p.NoCache = true
+		case "must-revalidate", "proxy-revalidate":
+			p.MustRevalidate = true
+		case "private":
+			p.Private = true
+		case "immutable":
+			p.Immutable = true
+		}
+	}
+	if secs, err := strconv.ParseInt(h.Get("Age"), 10, 64); err == nil && secs > 0 {
+		p.Age = time.Duration(secs) * time.Second
+	}
+
+	date := now
+	if d, err := http.ParseTime(h.Get("Date")); err == nil {
+		date = d
+	}
+	if p.MaxAge < 0 {
+		// Invalid dates, such as "0", mean that the response is already
+		// expired.
+		if e := h.Get("Expires"); e != "" {
+			p.MaxAge = 0
+			if exp, err := http.ParseTime(e); err == nil && exp.After(date) {
+				p.MaxAge = exp.Sub(date)
+			}
+		}
+	}
+
+	if !p.NoStore && !p.NoCache && p.MaxAge > p.Age {
+		p.FreshUntil = now.Add(p.MaxAge - p.Age)
+	}
+	return p
+}
@@ -0,0 +1,63 @@
+package xhr
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// The digest algorithms supported by SubtleCrypto.
+const (
+	SHA1   = "SHA-1"
+	SHA256 = "SHA-256"
+	SHA384 = "SHA-384"
+	SHA512 = "SHA-512"
+)
+
+// ErrSubtleCryptoUnavailable is returned by Digest when SubtleCrypto is
+// not available, such as outside of secure contexts.
+var ErrSubtleCryptoUnavailable = errors.New("SubtleCrypto not available")
+
+// Digest computes the digest of data with the SubtleCrypto algorithm,
+// such as SHA256. The browser's native implementation is considerably
+// faster than crypto/sha256 compiled by GopherJS for large downloads.
+func Digest(algorithm string, data []byte) ([]byte, error) {
+	subtle := js.Global.Get("crypto")
+	if subtle != js.Undefined {
+		subtle = subtle.Get("subtle")
+	}
+	if subtle == nil || subtle == js.Undefined {
+		return nil, ErrSubtleCryptoUnavailable
+	}
+
+	var p *js.Object
+	err := catch(func() {
+		p = subtle.Call("digest", algorithm, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf, err := await(p)
+	if err != nil {
+		return nil, err
+	}
+	return js.Global.Get("Uint8Array").New(buf).Interface().([]byte), nil
+}
+
+// verifyChecksum compares the digest of the ArrayBuffer response with
+// Checksum.
+func (r *Request) verifyChecksum() error {
+	algorithm := r.ChecksumAlgorithm
+	if algorithm == "" {
+		algorithm = SHA256
+	}
+	sum, err := Digest(algorithm, r.ResponseBytes())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sum, r.Checksum) {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
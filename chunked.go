@@ -0,0 +1,294 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// ChunkedUpload uploads a file or []byte in chunks of ChunkSize bytes,
+// sending one request per chunk with a "Content-Range" header. Failed
+// chunks are retried, and the upload can be paused and resumed. The
+// offset of the next chunk is kept, and optionally persisted in
+// localStorage, so that an upload can continue where it stopped after a
+// failure or a reload of the page.
+//
+// The server must accept chunks with status 2xx, or 308 Resume
+// Incomplete.
+type ChunkedUpload struct {
+	// Client sends the requests. A zero Client is used when nil.
+	Client *Client
+
+	URL string
+
+	// Method defaults to "POST".
+	Method string
+
+	// ChunkSize defaults to 1 MiB.
+	ChunkSize int64
+
+	// MaxRetries is the number of times a failed chunk is retried. It
+	// defaults to 3. Responses with status 4xx other than 408 and 429
+	// are not retried.
+	MaxRetries int
+
+	// StorageKey, if set, is the localStorage key under which the offset
+	// is persisted. It is removed when the upload completes.
+	StorageKey string
+
+	// MaxBytesPerSecond, if positive, caps the average upload
+	// throughput by pausing between chunks, so that background uploads
+	// don't saturate the uplink. Each chunk is still sent at full speed,
+	// so a smaller ChunkSize gives smoother pacing.
+	MaxBytesPerSecond int64
+
+	// OnProgress, if set, is called after every chunk. It must not
+	// block.
+	OnProgress func(UploadProgress)
+
+	blob *js.Object // Set for files and blobs
+	data []byte     // Set for []byte
+	size int64
+
+	mu     sync.Mutex
+	offset int64
+	paused bool
+	resume chan struct{}
+	cancel context.CancelFunc // Cancels the in-flight chunk
+}
+
+// NewChunkedUpload returns a ChunkedUpload of the file or blob b to url.
+func NewChunkedUpload(url string, b *BlobObject) *ChunkedUpload {
+	return &ChunkedUpload{URL: url, blob: b.Object, size: b.Size}
+}
+
+// NewChunkedUploadBytes returns a ChunkedUpload of data to url.
+func NewChunkedUploadBytes(url string, data []byte) *ChunkedUpload {
+	return &ChunkedUpload{URL: url, data: data, size: int64(len(data))}
+}
+
+// Offset returns the number of bytes that have been uploaded.
+func (u *ChunkedUpload) Offset() int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.offset
+}
+
+// SetOffset sets the offset at which the upload continues, for example
+// after the server reported how much it has received.
+func (u *ChunkedUpload) SetOffset(offset int64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.offset = offset
+}
+
+// Pause stops the upload after aborting the chunk in flight, which is
+// sent again on Resume. Start blocks until Resume is called.
+func (u *ChunkedUpload) Pause() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.paused {
+		return
+	}
+	u.paused = true
+	u.resume = make(chan struct{})
+	if u.cancel != nil {
+		u.cancel()
+	}
+}
+
+// Resume continues a paused upload.
+func (u *ChunkedUpload) Resume() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.paused {
+		u.paused = false
+		close(u.resume)
+	}
+}
+
+// Start uploads the remaining chunks and blocks until all of them have
+// been uploaded, a chunk failed after all retries or ctx is done. If
+// StorageKey is set, the upload starts at the persisted offset.
+func (u *ChunkedUpload) Start(ctx context.Context) error {
+	c := u.Client
+	if c == nil {
+		c = &Client{}
+	}
+	chunkSize := u.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = 1 << 20
+	}
+	maxRetries := u.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = 3
+	}
+	u.loadOffset()
+
+	retries := 0
+	for {
+		u.mu.Lock()
+		offset, paused, resume := u.offset, u.paused, u.resume
+		u.mu.Unlock()
+
+		if paused {
+			select {
+			case <-resume:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if offset >= u.size {
+			u.clearOffset()
+			return nil
+		}
+
+		end := offset + chunkSize
+		if end > u.size {
+			end = u.size
+		}
+		chunkCtx, cancel := context.WithCancel(ctx)
+		u.mu.Lock()
+		u.cancel = cancel
+		u.mu.Unlock()
+		start := time.Now()
+		err := u.sendChunk(chunkCtx, c, offset, end)
+		cancel()
+
+		switch {
+		case err == nil:
+			retries = 0
+			u.mu.Lock()
+			u.offset = end
+			u.mu.Unlock()
+			u.saveOffset(end)
+			if u.OnProgress != nil {
+				u.OnProgress(UploadProgress{BytesSent: end, Total: u.size, Percent: float64(end) / float64(u.size) * 100})
+			}
+			if err := u.throttle(ctx, end-offset, time.Since(start)); err != nil {
+				return err
+			}
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case u.isPaused():
+			// Sent again on Resume
+		case retries < maxRetries && retryableChunkError(err):
+			retries++
+			select {
+			case <-time.After(time.Duration(retries) * time.Second):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		default:
+			return err
+		}
+	}
+}
+
+// throttle waits until sending n bytes in elapsed time doesn't exceed
+// MaxBytesPerSecond.
+func (u *ChunkedUpload) throttle(ctx context.Context, n int64, elapsed time.Duration) error {
+	if u.MaxBytesPerSecond <= 0 {
+		return nil
+	}
+	wait := time.Duration(float64(n)/float64(u.MaxBytesPerSecond)*float64(time.Second)) - elapsed
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (u *ChunkedUpload) isPaused() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.paused
+}
+
+// sendChunk uploads the bytes from start up to end.
+func (u *ChunkedUpload) sendChunk(ctx context.Context, c *Client, start, end int64) error {
+	method := u.Method
+	if method == "" {
+		method = "POST"
+	}
+	req := c.NewRequest(method, u.URL)
+	req.SetRequestHeader("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end-1, u.size))
+
+	var body interface{}
+	if u.blob != nil {
+		body = u.blob.Call("slice", start, end)
+	} else {
+		body = u.data[start:end]
+	}
+	if err := c.Do(ctx, req, body); err != nil {
+		return err
+	}
+	if !req.IsStatus2xx() && req.Status != http.StatusPermanentRedirect {
+		return NewStatusError(req)
+	}
+	return nil
+}
+
+// retryableChunkError reports whether a chunk that failed with err should
+// be sent again.
+func retryableChunkError(err error) bool {
+	var se *StatusError
+	if !errors.As(err, &se) {
+		return true
+	}
+	switch code := se.StatusCode(); {
+	case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests:
+		return true
+	case code >= 400 && code < 500:
+		return false
+	}
+	return true
+}
+
+func (u *ChunkedUpload) storage() *js.Object {
+	if u.StorageKey == "" {
+		return nil
+	}
+	s := js.Global.Get("localStorage")
+	if s == js.Undefined || s == nil {
+		return nil
+	}
+	return s
+}
+
+func (u *ChunkedUpload) loadOffset() {
+	s := u.storage()
+	if s == nil {
+		return
+	}
+	v := s.Call("getItem", u.StorageKey)
+	if v == nil {
+		return
+	}
+	if offset, err := strconv.ParseInt(v.String(), 10, 64); err == nil && offset <= u.size {
+		u.SetOffset(offset)
+	}
+}
+
+func (u *ChunkedUpload) saveOffset(offset int64) {
+	if s := u.storage(); s != nil {
+		s.Call("setItem", u.StorageKey, strconv.FormatInt(offset, 10))
+	}
+}
+
+func (u *ChunkedUpload) clearOffset() {
+	if s := u.storage(); s != nil {
+		s.Call("removeItem", u.StorageKey)
+	}
+}
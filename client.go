@@ -0,0 +1,81 @@
+package xhr
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// Handler sends a request prepared by a Client.
+type Handler func(ctx context.Context, req *Request, data interface{}) error
+
+// Middleware wraps a Handler to add behaviour such as authentication,
+// logging or retries.
+type Middleware func(next Handler) Handler
+
+// Client holds configuration that is shared by multiple requests.
+// The zero value is ready to use.
+type Client struct {
+	// BaseURL is prepended to request urls that are not absolute.
+	BaseURL string
+
+	// Header contains headers that are set on every request.
+	Header http.Header
+
+	// WithCredentials is applied to every request.
+	WithCredentials bool
+
+	// Middleware is applied to every request sent with Do. The first
+	// element is the outermost.
+	Middleware []Middleware
+}
+
+// NewRequest creates a new Request configured with the client's
+// settings.
+func (c *Client) NewRequest(method, url string) *Request {
+	return c.newRequest(method, c.resolve(url))
+}
+
+// newRequest is like NewRequest for urls that have been resolved
+// against BaseURL already.
+func (c *Client) newRequest(method, url string) *Request {
+	req := NewRequest(method, url)
+	req.WithCredentials = c.WithCredentials
+	req.SetRequestHeaders(c.Header)
+	return req
+}
+
+// Do sends req through the client's middleware.
+func (c *Client) Do(ctx context.Context, req *Request, data interface{}) error {
+	h := Handler(func(ctx context.Context, req *Request, data interface{}) error {
+		return req.Send(ctx, data)
+	})
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		h = c.Middleware[i](h)
+	}
+	return h(ctx, req, data)
+}
+
+// resolve prepends BaseURL to url if it is not absolute.
+func (c *Client) resolve(url string) string {
+	if c.BaseURL == "" || isAbsURL(url) {
+		return url
+	}
+	return strings.TrimSuffix(c.BaseURL, "/") + "/" + strings.TrimPrefix(url, "/")
+}
+
+// locationHref returns the url of the current page, or an empty string
+// outside of browsers.
+func locationHref() string {
+	loc := js.Global.Get("location")
+	if loc == js.Undefined {
+		return ""
+	}
+	return loc.Get("href").String()
+}
+
+func isAbsURL(url string) bool {
+	return strings.HasPrefix(url, "//") || strings.Contains(url, "://")
+}
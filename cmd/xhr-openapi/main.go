@@ -0,0 +1,466 @@
+// Command xhr-openapi generates a typed client for package xhr from an
+// OpenAPI 3 specification in JSON format. YAML specifications must be
+// converted to JSON first.
+//
+// It is intended to be used with go generate:
+//
+//	//go:generate go run github.com/rocketlaunchr/gopherjs-xhr/cmd/xhr-openapi -spec api.json -pkg api -o api_gen.go
+//
+// Structs and enums are generated for components.schemas. A method is
+// generated on Client for every operation with an operationId. Path
+// parameters become arguments, query parameters are passed as
+// url.Values and JSON request and response bodies are mapped to the
+// generated types.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type spec struct {
+	Paths      map[string]*pathItem `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// pathItem is a Path Item Object. Its parameters apply to all of its
+// operations.
+type pathItem struct {
+	Summary     string            `json:"summary"`
+	Description string            `json:"description"`
+	Servers     []json.RawMessage `json:"servers"`
+	Parameters  []*parameter      `json:"parameters"`
+
+	Get     *operation `json:"get"`
+	Put     *operation `json:"put"`
+	Post    *operation `json:"post"`
+	Delete  *operation `json:"delete"`
+	Patch   *operation `json:"patch"`
+	Head    *operation `json:"head"`
+	Options *operation `json:"options"`
+	Trace   *operation `json:"trace"`
+}
+
+// operations returns the operations of the path item by method, in a
+// fixed order.
+func (p *pathItem) operations() []struct {
+	method string
+	op     *operation
+} {
+	return []struct {
+		method string
+		op     *operation
+	}{
+		{"GET", p.Get}, {"PUT", p.Put}, {"POST", p.Post}, {"DELETE", p.Delete},
+		{"PATCH", p.Patch}, {"HEAD", p.Head}, {"OPTIONS", p.Options}, {"TRACE", p.Trace},
+	}
+}
+
+// mergeParameters returns the parameters of op, followed by the
+// parameters of the path item that op doesn't override. A parameter is
+// identified by its name and location.
+func mergeParameters(pathParams, opParams []*parameter) []*parameter {
+	params := append([]*parameter(nil), opParams...)
+	for _, pp := range pathParams {
+		overridden := false
+		for _, op := range opParams {
+			if op.Name == pp.Name && op.In == pp.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			params = append(params, pp)
+		}
+	}
+	return params
+}
+
+type operation struct {
+	OperationID string       `json:"operationId"`
+	Summary     string       `json:"summary"`
+	Parameters  []*parameter `json:"parameters"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]struct {
+			Schema *schema `json:"schema"`
+		} `json:"content"`
+	} `json:"responses"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Description          string             `json:"description"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *schema            `json:"items"`
+	Enum                 []interface{}      `json:"enum"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+}
+
+func main() {
+	specPath := flag.String("spec", "", "path of the OpenAPI 3 specification in JSON format")
+	pkg := flag.String("pkg", "api", "package name of the generated code")
+	out := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	if *specPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	b, err := ioutil.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var s spec
+	err = json.Unmarshal(b, &s)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := generate(&s, *pkg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	err = ioutil.WriteFile(*out, src, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func generate(s *spec, pkg string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by xhr-openapi. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	buf.WriteString(`import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/rocketlaunchr/react/forks/encoding/json"
+
+	xhr "github.com/rocketlaunchr/gopherjs-xhr"
+)
+
+var (
+	_ = fmt.Sprint
+	_ = url.PathEscape
+)
+
+// Client is a typed client for the API.
+type Client struct {
+	*xhr.Client
+}
+
+// NewClient returns a Client for the API at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{Client: &xhr.Client{BaseURL: baseURL}}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	req := c.NewRequest(method, path)
+	req.ResponseType = xhr.Text
+	req.SetRequestHeader("Accept", xhr.ApplicationJSON)
+
+	var data interface{}
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+		req.SetRequestHeader("Content-Type", xhr.ApplicationJSON)
+	}
+
+	err := c.Do(ctx, req, data)
+	if err != nil {
+		return err
+	}
+	if !req.IsStatus2xx() {
+		return xhr.NewStatusError(req)
+	}
+	if out == nil || req.ResponseText == "" {
+		return nil
+	}
+	return json.Unmarshal(req.ResponseBytes(), out)
+}
+`)
+
+	names := make([]string, 0, len(s.Components.Schemas))
+	for name := range s.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeSchema(&buf, exported(name), s.Components.Schemas[name])
+	}
+
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		item := s.Paths[path]
+		if item == nil {
+			continue
+		}
+		for _, m := range item.operations() {
+			if m.op == nil || m.op.OperationID == "" {
+				continue
+			}
+			op := *m.op
+			op.Parameters = mergeParameters(item.Parameters, op.Parameters)
+			writeOperation(&buf, m.method, path, &op)
+		}
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+func writeSchema(buf *bytes.Buffer, name string, s *schema) {
+	if s.Description != "" {
+		writeComment(buf, name+" "+s.Description)
+	} else {
+		fmt.Fprintf(buf, "\n// %s is generated from the %s schema.\n", name, name)
+	}
+
+	if s.Type == "string" && len(s.Enum) > 0 {
+		fmt.Fprintf(buf, "type %s string\n\nconst (\n", name)
+		for _, v := range s.Enum {
+			str := fmt.Sprint(v)
+			fmt.Fprintf(buf, "\t%s%s %s = %q\n", name, exported(str), name, str)
+		}
+		buf.WriteString(")\n")
+		return
+	}
+
+	if s.Type != "object" && s.Properties == nil {
+		fmt.Fprintf(buf, "type %s %s\n", name, goType(s))
+		return
+	}
+
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	props := make([]string, 0, len(s.Properties))
+	for p := range s.Properties {
+		props = append(props, p)
+	}
+	sort.Strings(props)
+
+	fmt.Fprintf(buf, "type %s struct {\n", name)
+	for _, p := range props {
+		ps := s.Properties[p]
+		if ps.Description != "" {
+			writeComment(buf, ps.Description)
+		}
+		typ, tag := goType(ps), p
+		if !required[p] {
+			tag += ",omitempty"
+			if ps.Ref != "" {
+				typ = "*" + typ
+			}
+		}
+		fmt.Fprintf(buf, "\t%s %s `json:%q`\n", exported(p), typ, tag)
+	}
+	buf.WriteString("}\n")
+}
+
+func writeOperation(buf *bytes.Buffer, method, path string, op *operation) {
+	name := exported(op.OperationID)
+
+	args := []string{"ctx context.Context"}
+	pathExpr := fmt.Sprintf("%q", path)
+	hasQuery := false
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			arg := unexported(p.Name)
+			args = append(args, arg+" "+goType(p.Schema))
+			pathExpr = strings.Replace(pathExpr, "{"+p.Name+"}", `" + url.PathEscape(fmt.Sprint(`+arg+`)) + "`, 1)
+		case "query":
+			hasQuery = true
+		}
+	}
+	pathExpr = strings.TrimSuffix(pathExpr, ` + ""`)
+	if hasQuery {
+		args = append(args, "query url.Values")
+	}
+	query := "nil"
+	if hasQuery {
+		query = "query"
+	}
+
+	in := "nil"
+	if op.RequestBody != nil {
+		if c, ok := op.RequestBody.Content["application/json"]; ok && c.Schema != nil {
+			args = append(args, "body "+goType(c.Schema))
+			in = "body"
+		}
+	}
+
+	var out *schema
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		if c, ok := op.Responses[code].Content["application/json"]; ok && c.Schema != nil {
+			out = c.Schema
+			break
+		}
+	}
+
+	comment := name + " sends " + method + " " + path + "."
+	if op.Summary != "" {
+		comment = name + ": " + op.Summary
+	}
+	writeComment(buf, comment)
+
+	if out == nil {
+		fmt.Fprintf(buf, "func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+		fmt.Fprintf(buf, "\treturn c.do(ctx, %q, %s, %s, %s, nil)\n}\n", method, pathExpr, query, in)
+		return
+	}
+	typ := goType(out)
+	fmt.Fprintf(buf, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), typ)
+	fmt.Fprintf(buf, "\tvar out %s\n", typ)
+	fmt.Fprintf(buf, "\terr := c.do(ctx, %q, %s, %s, %s, &out)\n", method, pathExpr, query, in)
+	buf.WriteString("\treturn out, err\n}\n")
+}
+
+func goType(s *schema) string {
+	if s == nil {
+		return "interface{}"
+	}
+	if s.Ref != "" {
+		return exported(s.Ref[strings.LastIndex(s.Ref, "/")+1:])
+	}
+	switch s.Type {
+	case "string":
+		return "string"
+	case "integer":
+		if s.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + goType(s.Items)
+	case "object":
+		if len(s.AdditionalProperties) > 0 && s.AdditionalProperties[0] == '{' {
+			var ap schema
+			if json.Unmarshal(s.AdditionalProperties, &ap) == nil {
+				return "map[string]" + goType(&ap)
+			}
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+func writeComment(buf *bytes.Buffer, text string) {
+	buf.WriteString("\n")
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		buf.WriteString("// " + line + "\n")
+	}
+}
+
+// exported converts name, such as "get_user-byId", into an exported Go
+// identifier, such as "GetUserByID".
+func exported(name string) string {
+	var words []string
+	for _, field := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		// Split camelCase, e.g. "getUserById" -> "get", "User", "By", "Id".
+		start := 0
+		for i := 1; i < len(field); i++ {
+			if unicode.IsUpper(rune(field[i])) && unicode.IsLower(rune(field[i-1])) {
+				words = append(words, field[start:i])
+				start = i
+			}
+		}
+		words = append(words, field[start:])
+	}
+
+	var sb strings.Builder
+	for _, word := range words {
+		switch strings.ToLower(word) {
+		case "id", "url", "uri", "http", "json", "xml", "api", "uuid":
+			sb.WriteString(strings.ToUpper(word))
+		default:
+			sb.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	s := sb.String()
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "X" + s
+	}
+	return s
+}
+
+func unexported(name string) string {
+	s := exported(name)
+	i := 0
+	for i < len(s) && unicode.IsUpper(rune(s[i])) {
+		i++
+	}
+	if i > 1 && i < len(s) {
+		i-- // Keep the start of the next word, e.g. IDFoo -> idFoo
+	}
+	s = strings.ToLower(s[:i]) + s[i:]
+	switch s {
+	case "type", "func", "map", "range", "select", "default", "package", "var", "go", "chan", "case", "ctx", "query", "body", "out", "err", "c":
+		s += "Param"
+	}
+	return s
+}
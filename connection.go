@@ -0,0 +1,77 @@
+package xhr
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// latencyProbes is the number of requests used to estimate the RTT.
+const latencyProbes = 5
+
+// ConnectionStats are estimates of the connection's quality.
+type ConnectionStats struct {
+	// RTT is the median round-trip time of small requests.
+	RTT time.Duration
+
+	// Bandwidth is the download throughput in bytes per second.
+	Bandwidth float64
+}
+
+// MeasureConnection estimates the round-trip time and bandwidth of the
+// connection to the server hosting probeURL, so that apps can adapt
+// image sizes or polling intervals to the connection.
+//
+// The RTT is measured with a few HEAD requests. The bandwidth is measured
+// by downloading probeURL once, which should therefore serve a body of at
+// least a few hundred kilobytes. Caching is defeated with a query
+// parameter.
+func MeasureConnection(ctx context.Context, probeURL string) (ConnectionStats, error) {
+	var stats ConnectionStats
+
+	rtts := make([]time.Duration, 0, latencyProbes)
+	for i := 0; i < latencyProbes; i++ {
+		req, d, err := probe(ctx, "HEAD", probeURL)
+		if err != nil {
+			return stats, err
+		}
+		if !req.IsStatus2xx() {
+			return stats, NewStatusError(req)
+		}
+		rtts = append(rtts, d)
+	}
+	sort.Slice(rtts, func(i, j int) bool { return rtts[i] < rtts[j] })
+	stats.RTT = rtts[len(rtts)/2]
+
+	req, d, err := probe(ctx, "GET", probeURL)
+	if err != nil {
+		return stats, err
+	}
+	if !req.IsStatus2xx() {
+		return stats, NewStatusError(req)
+	}
+
+	transfer := d - stats.RTT // Exclude the time to first byte
+	if transfer <= 0 {
+		transfer = d
+	}
+	if size := req.Response.Get("byteLength").Int(); size > 0 && transfer > 0 {
+		stats.Bandwidth = float64(size) / transfer.Seconds()
+	}
+	return stats, nil
+}
+
+// probe sends an uncached request and returns how long it took.
+func probe(ctx context.Context, method, url string) (*Request, time.Duration, error) {
+	u, err := setQuery(url, "_", strconv.FormatInt(time.Now().UnixNano(), 36))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req := NewRequest(method, u)
+	req.ResponseType = ArrayBuffer
+	start := time.Now()
+	err = req.Send(ctx, nil)
+	return req, time.Since(start), err
+}
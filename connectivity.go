@@ -0,0 +1,64 @@
+package xhr
+
+import (
+	"context"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// Connectivity is the state of the network connection.
+type Connectivity int
+
+// The possible values of Connectivity.
+const (
+	// Online means the probe endpoint was reached unaltered.
+	Online Connectivity = iota
+	// Offline means there is no network connection.
+	Offline
+	// CaptivePortal means a network is available but requests are
+	// intercepted, e.g. by a hotel login page or DNS hijacking.
+	CaptivePortal
+)
+
+func (c Connectivity) String() string {
+	switch c {
+	case Online:
+		return "online"
+	case Offline:
+		return "offline"
+	case CaptivePortal:
+		return "captive portal"
+	}
+	return "unknown"
+}
+
+// CheckConnectivity distinguishes being truly offline from being behind
+// a captive portal. probeURL must respond with 204 No Content and allow
+// cross-origin requests if necessary. Any other response, or being
+// redirected elsewhere, indicates that requests are intercepted.
+//
+// Portals that redirect to another origin typically make the request
+// fail a CORS check, which can't be told apart from being offline, so
+// the result is a best effort. An error is only returned if ctx is done.
+func CheckConnectivity(ctx context.Context, probeURL string) (Connectivity, error) {
+	if nav := js.Global.Get("navigator"); nav != js.Undefined && nav.Get("onLine") != js.Undefined && !nav.Get("onLine").Bool() {
+		return Offline, nil
+	}
+
+	req := NewRequest("GET", probeURL)
+	err := req.Send(ctx, nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return Offline, ctx.Err()
+		}
+		return Offline, nil
+	}
+
+	if req.Status != 204 {
+		return CaptivePortal, nil
+	}
+	if final := req.Get("responseURL"); final != js.Undefined && final.String() != "" && final.String() != resolveURL(locationHref(), probeURL) {
+		return CaptivePortal, nil
+	}
+	return Online, nil
+}
@@ -0,0 +1,114 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// ErrMissingCookie is returned by requests sent through RequireCookies
+// when a required cookie is not present.
+var ErrMissingCookie = errors.New("required cookie missing")
+
+// Cookie describes a cookie written with SetCookie.
+type Cookie struct {
+	Name  string
+	Value string
+
+	Path   string
+	Domain string
+
+	// Expires is ignored when zero.
+	Expires time.Time
+
+	// MaxAge is the lifetime in seconds. It is ignored when zero.
+	MaxAge int
+
+	Secure bool
+
+	// SameSite is "Strict", "Lax" or "None". It is ignored when empty.
+	SameSite string
+}
+
+// Cookies returns the cookies accessible via document.cookie. HttpOnly
+// cookies are never accessible.
+func Cookies() map[string]string {
+	m := map[string]string{}
+	for _, pair := range strings.Split(js.Global.Get("document").Get("cookie").String(), ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, value := pair, ""
+		if i := strings.IndexByte(pair, '='); i >= 0 {
+			name, value = pair[:i], pair[i+1:]
+		}
+		if v, err := url.PathUnescape(value); err == nil {
+			value = v
+		}
+		m[name] = value
+	}
+	return m
+}
+
+// GetCookie returns the value of the named cookie.
+func GetCookie(name string) (string, bool) {
+	value, ok := Cookies()[name]
+	return value, ok
+}
+
+// SetCookie writes a cookie via document.cookie. The value is escaped
+// and unescaped again by Cookies.
+func SetCookie(c Cookie) {
+	s := c.Name + "=" + url.PathEscape(c.Value)
+	if c.Path != "" {
+		s += "; Path=" + c.Path
+	}
+	if c.Domain != "" {
+		s += "; Domain=" + c.Domain
+	}
+	if !c.Expires.IsZero() {
+		s += "; Expires=" + c.Expires.UTC().Format(http.TimeFormat)
+	}
+	if c.MaxAge != 0 {
+		s += "; Max-Age=" + strconv.Itoa(c.MaxAge)
+	}
+	if c.Secure {
+		s += "; Secure"
+	}
+	if c.SameSite != "" {
+		s += "; SameSite=" + c.SameSite
+	}
+	js.Global.Get("document").Set("cookie", s)
+}
+
+// DeleteCookie removes the named cookie. path and domain must match the
+// values the cookie was set with.
+func DeleteCookie(name, path, domain string) {
+	SetCookie(Cookie{Name: name, Path: path, Domain: domain, MaxAge: -1})
+}
+
+// RequireCookies returns a Middleware that fails requests with an error
+// wrapping ErrMissingCookie when any of the named cookies is absent,
+// instead of sending a request that will be rejected with a mysterious
+// 401. HttpOnly cookies can't be checked and must not be listed.
+func RequireCookies(names ...string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request, data interface{}) error {
+			cookies := Cookies()
+			for _, name := range names {
+				if _, ok := cookies[name]; !ok {
+					return fmt.Errorf("%w: %s", ErrMissingCookie, name)
+				}
+			}
+			return next(ctx, req, data)
+		}
+	}
+}
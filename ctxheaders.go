@@ -0,0 +1,57 @@
+package xhr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ContextKey is the type of the well-known context keys read by
+// ContextHeaders.
+type ContextKey string
+
+// Well-known context keys for cross-cutting request metadata.
+const (
+	TenantIDKey     ContextKey = "tenant-id"
+	LocaleKey       ContextKey = "locale"
+	RequestIDKey    ContextKey = "request-id"
+	FeatureFlagsKey ContextKey = "feature-flags"
+)
+
+// DefaultContextHeaders maps the well-known context keys to their
+// conventional headers.
+var DefaultContextHeaders = map[interface{}]string{
+	TenantIDKey:     "X-Tenant-ID",
+	LocaleKey:       "Accept-Language",
+	RequestIDKey:    "X-Request-ID",
+	FeatureFlagsKey: "X-Feature-Flags",
+}
+
+// ContextHeaders returns a Middleware that sets a header for every
+// context key of headers that has a value in the request's context. This
+// lets metadata such as the tenant or locale flow from UI state to the
+// API without threading parameters through every call:
+//
+//	client.Middleware = append(client.Middleware, xhr.ContextHeaders(xhr.DefaultContextHeaders))
+//	ctx = context.WithValue(ctx, xhr.TenantIDKey, "acme")
+//
+// A []string value is joined with ", ". Other values are formatted with
+// fmt.Sprint.
+func ContextHeaders(headers map[interface{}]string) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request, data interface{}) error {
+			for key, header := range headers {
+				switch v := ctx.Value(key).(type) {
+				case nil:
+				case string:
+					req.SetRequestHeader(header, v)
+				case []string:
+					req.SetRequestHeader(header, strings.Join(v, ", "))
+				default:
+					req.SetRequestHeader(header, fmt.Sprint(v))
+				}
+			}
+			return next(ctx, req, data)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package xhr
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// ResponseBase64 returns the response body, as returned by
+// ResponseBytes, encoded with standard base64.
+func (r *Request) ResponseBase64() string {
+	return base64.StdEncoding.EncodeToString(r.ResponseBytes())
+}
+
+// ResponseDataURL returns the response body as a data URL, using the
+// response's "Content-Type", or SniffContentType if it is missing or
+// "application/octet-stream". This allows fetched images and fonts to be
+// embedded directly into the DOM without managing blob URLs.
+func (r *Request) ResponseDataURL() string {
+	mediaType := r.ResponseHeader("Content-Type")
+	if mediaType == "" || strings.HasPrefix(mediaType, "application/octet-stream") {
+		mediaType = r.SniffContentType()
+	}
+	return DataURL(mediaType, r.ResponseBytes())
+}
+
+// DataURL returns a base64-encoded data URL for data with the given media
+// type, which defaults to "application/octet-stream".
+func DataURL(mediaType string, data []byte) string {
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+	mediaType = strings.ReplaceAll(mediaType, " ", "") // Parameters as in "text/plain; charset=utf-8"
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
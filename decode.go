@@ -0,0 +1,120 @@
+package xhr
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupportedMediaType is returned by Decode when no decoder is
+// registered for the response's "Content-Type", and by Send when no
+// encoder is registered for the request's.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// DecoderFunc decodes the response of a completed request into v.
+type DecoderFunc func(r *Request, v interface{}) error
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]DecoderFunc{
+		ApplicationJSON:   decodeJSON,
+		"application/xml": decodeXML,
+		"text/xml":        decodeXML,
+		ApplicationForm:   decodeForm,
+		TextPlain:         decodeText,
+		ApplicationGob:    decodeGob,
+	}
+)
+
+// ValidatorFunc checks the response of a completed request before it is
+// decoded. See package jsonschema for a JSON Schema validator.
+type ValidatorFunc func(r *Request) error
+
+// RegisterDecoder registers fn as the decoder used by Decode for
+// responses of mediaType, such as "application/msgpack". It replaces any
+// decoder already registered for mediaType.
+func RegisterDecoder(mediaType string, fn DecoderFunc) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[strings.ToLower(mediaType)] = fn
+}
+
+// Decode decodes the response into v, selecting the decoder by the
+// response's "Content-Type". Decoders for JSON, XML, form, text and gob
+// responses are built in, and others can be added with RegisterDecoder.
+// Media types with a "+json" or "+xml" suffix, such as
+// "application/problem+json", are decoded as JSON or XML.
+//
+// Text responses can be decoded into a *string or *[]byte, and form
+// responses into a *url.Values.
+//
+// If the request's Validator is set, it is called first and its error is
+// returned.
+func (r *Request) Decode(v interface{}) error {
+	if r.Validator != nil {
+		if err := r.Validator(r); err != nil {
+			return err
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.ResponseHeader("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedMediaType, err)
+	}
+
+	decodersMu.RLock()
+	fn := decoders[mediaType]
+	decodersMu.RUnlock()
+
+	if fn == nil {
+		switch {
+		case strings.HasSuffix(mediaType, "+json"):
+			fn = decodeJSON
+		case strings.HasSuffix(mediaType, "+xml"):
+			fn = decodeXML
+		default:
+			return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+		}
+	}
+	return fn(r, v)
+}
+
+func decodeJSON(r *Request, v interface{}) error {
+	return r.JSON(v)
+}
+
+func decodeXML(r *Request, v interface{}) error {
+	return r.XML(v)
+}
+
+func decodeForm(r *Request, v interface{}) error {
+	values, ok := v.(*url.Values)
+	if !ok {
+		return fmt.Errorf("xhr: cannot decode form into %T", v)
+	}
+	vals, err := url.ParseQuery(string(r.ResponseBytes()))
+	if err != nil {
+		return err
+	}
+	*values = vals
+	return nil
+}
+
+func decodeText(r *Request, v interface{}) error {
+	switch v := v.(type) {
+	case *string:
+		*v = string(r.ResponseBytes())
+	case *[]byte:
+		*v = r.ResponseBytes()
+	default:
+		return fmt.Errorf("xhr: cannot decode text into %T", v)
+	}
+	return nil
+}
+
+func decodeGob(r *Request, v interface{}) error {
+	return r.Gob(v)
+}
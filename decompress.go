@@ -0,0 +1,174 @@
+package xhr
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// The possible values of Request.Decompress.
+const (
+	DecompressGzip       = "gzip"
+	DecompressDeflate    = "deflate"     // zlib-wrapped deflate
+	DecompressDeflateRaw = "deflate-raw" // deflate without a zlib wrapper
+	// DecompressBrotli requires a browser whose DecompressionStream
+	// supports Brotli, or BrotliDecoder to be set.
+	DecompressBrotli = "br"
+	// DecompressAuto detects gzip and zlib data by their headers, and
+	// Brotli data by a "Content-Encoding" of "br". It leaves other
+	// responses unchanged, as well as responses that merely look
+	// compressed and fail to decompress.
+	DecompressAuto = "auto"
+)
+
+// ErrBrotliUnsupported is returned by Send when a response must be
+// decompressed with Brotli, but neither the browser nor BrotliDecoder
+// supports it.
+var ErrBrotliUnsupported = errors.New("brotli decompression not supported")
+
+// BrotliDecoder decompresses Brotli data when the browser's
+// DecompressionStream doesn't support it. It is nil by default, since no
+// Brotli decoder is bundled. Use SetJSBrotliDecoder to plug in a
+// JavaScript or WebAssembly implementation.
+var BrotliDecoder func(data []byte) ([]byte, error)
+
+// SetJSBrotliDecoder sets BrotliDecoder to the JavaScript function fn,
+// such as the decompress function of the brotli-wasm package. fn is
+// called with a Uint8Array and must return a Uint8Array or a Promise of
+// one.
+func SetJSBrotliDecoder(fn *js.Object) {
+	BrotliDecoder = func(data []byte) (out []byte, err error) {
+		var res *js.Object
+		err = catch(func() {
+			res = fn.Invoke(data)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if res != nil && res.Get("then") != js.Undefined {
+			if res, err = await(res); err != nil {
+				return nil, err
+			}
+		}
+		return js.Global.Get("Uint8Array").New(res).Interface().([]byte), nil
+	}
+}
+
+// decompress replaces the ArrayBuffer response's bytes with their
+// decompressed form.
+func (r *Request) decompress() error {
+	if r.ResponseType != ArrayBuffer || r.Response == nil {
+		return nil
+	}
+	data := r.ResponseArrayBufferBytes(true)
+
+	format := r.Decompress
+	auto := format == DecompressAuto
+	if auto {
+		format = detectCompression(data)
+		if format == "" && strings.EqualFold(r.ResponseHeader("Content-Encoding"), DecompressBrotli) {
+			format = DecompressBrotli
+		}
+		if format == "" {
+			return nil
+		}
+	}
+
+	b, err := decompressBytes(data, format)
+	if err != nil {
+		if auto {
+			return nil // Keep the raw bytes
+		}
+		return err
+	}
+	r.bodyView = b
+	return nil
+}
+
+// detectCompression returns the compression format of data, or an empty
+// string if it isn't recognized.
+func detectCompression(data []byte) string {
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		return DecompressGzip
+	case len(data) >= 2 && isZlibHeader(data[0], data[1]):
+		return DecompressDeflate
+	}
+	return ""
+}
+
+// isZlibHeader reports whether cmf and flg form a zlib header for
+// deflate data with a window of at most 32KiB and no preset dictionary.
+func isZlibHeader(cmf, flg byte) bool {
+	return cmf&0x0f == 8 && // CM: deflate
+		cmf>>4 <= 7 && // CINFO: window size
+		flg&0x20 == 0 && // FDICT: no preset dictionary
+		(uint16(cmf)<<8|uint16(flg))%31 == 0 // FCHECK
+}
+
+// decompressBytes decompresses data using the browser's
+// DecompressionStream where available, and the standard library
+// otherwise.
+func decompressBytes(data []byte, format string) ([]byte, error) {
+	switch format {
+	case DecompressGzip, DecompressDeflate, DecompressDeflateRaw:
+	case DecompressBrotli:
+		return decompressBrotli(data)
+	default:
+		return nil, fmt.Errorf("unsupported compression format %q", format)
+	}
+
+	if ds := js.Global.Get("DecompressionStream"); ds != js.Undefined {
+		return pipeThrough(data, ds.New(format))
+	}
+
+	var (
+		rd  io.Reader
+		err error
+	)
+	switch format {
+	case DecompressGzip:
+		rd, err = gzip.NewReader(bytes.NewReader(data))
+	case DecompressDeflate:
+		rd, err = zlib.NewReader(bytes.NewReader(data))
+	default:
+		rd = flate.NewReader(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(rd)
+}
+
+// decompressBrotli decompresses data with DecompressionStream if the
+// browser supports the "brotli" format, and BrotliDecoder otherwise.
+func decompressBrotli(data []byte) ([]byte, error) {
+	if ds := js.Global.Get("DecompressionStream"); ds != js.Undefined {
+		var dec *js.Object
+		if catch(func() { dec = ds.New("brotli") }) == nil {
+			return pipeThrough(data, dec)
+		}
+	}
+	if BrotliDecoder == nil {
+		return nil, ErrBrotliUnsupported
+	}
+	return BrotliDecoder(data)
+}
+
+// pipeThrough passes data through the TransformStream ts and returns the
+// result.
+func pipeThrough(data []byte, ts *js.Object) ([]byte, error) {
+	stream := js.Global.Get("Blob").New([]interface{}{data}).Call("stream").Call("pipeThrough", ts)
+	buf, err := await(js.Global.Get("Response").New(stream).Call("arrayBuffer"))
+	if err != nil {
+		return nil, err
+	}
+	return js.Global.Get("Uint8Array").New(buf).Interface().([]byte), nil
+}
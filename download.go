@@ -0,0 +1,343 @@
+package xhr
+
+import (
+	"context"
+	"mime"
+	"net/url"
+	"path"
+	"sync"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// DownloadStatus is the state of a Download.
+type DownloadStatus int
+
+// The possible values of DownloadStatus.
+const (
+	DownloadQueued DownloadStatus = iota
+	DownloadRunning
+	DownloadPaused
+	DownloadDone
+	DownloadFailed
+	DownloadCanceled
+)
+
+// Download is an item of a DownloadManager.
+type Download struct {
+	URL      string
+	Filename string
+	Priority int
+
+	m      *DownloadManager
+	seq    int // Insertion order, used to break priority ties
+	status DownloadStatus
+	loaded int64
+	total  int64
+	err    error
+	cancel context.CancelFunc
+	run    int // Incremented for every run, so that stale runs can be ignored
+}
+
+// Status returns the current state of the download.
+func (d *Download) Status() DownloadStatus {
+	d.m.mu.Lock()
+	defer d.m.mu.Unlock()
+	return d.status
+}
+
+// Progress returns the number of bytes loaded and the total size, which
+// is 0 if unknown.
+func (d *Download) Progress() (loaded, total int64) {
+	d.m.mu.Lock()
+	defer d.m.mu.Unlock()
+	return d.loaded, d.total
+}
+
+// Err returns the error of a failed download.
+func (d *Download) Err() error {
+	d.m.mu.Lock()
+	defer d.m.mu.Unlock()
+	return d.err
+}
+
+// Pause stops a queued or running download. Since XMLHttpRequest can't
+// retain partial responses, a paused download starts over when resumed.
+func (d *Download) Pause() {
+	d.m.stop(d, DownloadPaused, DownloadQueued, DownloadRunning)
+}
+
+// Resume queues a paused download again.
+func (d *Download) Resume() {
+	d.m.mu.Lock()
+	if d.status == DownloadPaused {
+		d.status = DownloadQueued
+		d.loaded = 0
+	}
+	d.m.mu.Unlock()
+	d.m.schedule()
+}
+
+// Cancel stops the download permanently.
+func (d *Download) Cancel() {
+	d.m.stop(d, DownloadCanceled, DownloadQueued, DownloadRunning, DownloadPaused)
+}
+
+// DownloadManager queues downloads, runs a limited number of them
+// concurrently in order of priority and saves each completed download to
+// a file.
+type DownloadManager struct {
+	// Client sends the requests. A zero Client is used when nil.
+	Client *Client
+
+	// Concurrency is the maximum number of simultaneous downloads. It
+	// defaults to 3.
+	Concurrency int
+
+	// NoSave disables saving completed downloads to a file.
+	NoSave bool
+
+	// OnProgress, if set, is called whenever a download makes progress
+	// or changes state. It is called from event listeners and must not
+	// block.
+	OnProgress func(d *Download)
+
+	mu        sync.Mutex
+	downloads []*Download
+	running   int
+	seq       int
+}
+
+// Add queues a download of url that is saved as filename. Downloads with
+// a higher priority are started first.
+func (m *DownloadManager) Add(url, filename string, priority int) *Download {
+	m.mu.Lock()
+	m.seq++
+	d := &Download{URL: url, Filename: filename, Priority: priority, m: m, seq: m.seq}
+	m.downloads = append(m.downloads, d)
+	m.mu.Unlock()
+
+	m.schedule()
+	return d
+}
+
+// Downloads returns all downloads added to the manager.
+func (m *DownloadManager) Downloads() []*Download {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Download(nil), m.downloads...)
+}
+
+// Progress returns the aggregate number of bytes loaded and total size of
+// all downloads that are not canceled. total only includes downloads
+// whose size is known.
+func (m *DownloadManager) Progress() (loaded, total int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range m.downloads {
+		if d.status != DownloadCanceled {
+			loaded += d.loaded
+			total += d.total
+		}
+	}
+	return loaded, total
+}
+
+// schedule starts queued downloads while below the concurrency limit.
+func (m *DownloadManager) schedule() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	limit := m.Concurrency
+	if limit <= 0 {
+		limit = 3
+	}
+
+	for m.running < limit {
+		var next *Download
+		for _, d := range m.downloads {
+			if d.status != DownloadQueued {
+				continue
+			}
+			if next == nil || d.Priority > next.Priority || (d.Priority == next.Priority && d.seq < next.seq) {
+				next = d
+			}
+		}
+		if next == nil {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		next.status = DownloadRunning
+		next.cancel = cancel
+		next.run++
+		m.running++
+		go m.run(ctx, next, next.run)
+	}
+}
+
+// run performs the run'th run of d. A run that was paused or canceled
+// may complete after a new run has been started, in which case its
+// outcome is ignored.
+func (m *DownloadManager) run(ctx context.Context, d *Download, run int) {
+	c := m.Client
+	if c == nil {
+		c = &Client{}
+	}
+
+	req := c.NewRequest("GET", d.URL)
+	req.ResponseType = Blob
+	req.AddEventListener("progress", false, func(e *js.Object) {
+		m.mu.Lock()
+		if d.run != run {
+			m.mu.Unlock()
+			return
+		}
+		d.loaded = e.Get("loaded").Int64()
+		if e.Get("lengthComputable").Bool() {
+			d.total = e.Get("total").Int64()
+		}
+		m.mu.Unlock()
+		m.notify(d)
+	})
+
+	err := c.Do(ctx, req, nil)
+	if err == nil && !req.IsStatus2xx() {
+		err = NewStatusError(req)
+	}
+
+	m.mu.Lock()
+	m.running--
+	current := d.run == run && d.status == DownloadRunning // Otherwise paused, canceled or superseded
+	if current {
+		d.cancel = nil
+		if err != nil {
+			d.status = DownloadFailed
+			d.err = err
+		} else {
+			d.status = DownloadDone
+		}
+	}
+	done := current && d.status == DownloadDone
+	m.mu.Unlock()
+
+	if done && !m.NoSave {
+		saveBlob(req.Response, d.Filename)
+	}
+	if current {
+		m.notify(d)
+	}
+	m.schedule()
+}
+
+// stop moves d into status if it is currently in one of from.
+func (m *DownloadManager) stop(d *Download, status DownloadStatus, from ...DownloadStatus) {
+	m.mu.Lock()
+	var cancel context.CancelFunc
+	for _, s := range from {
+		if d.status == s {
+			if s == DownloadRunning {
+				cancel = d.cancel
+			}
+			d.status = status
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel() // run reschedules once the request has been aborted
+	}
+	m.notify(d)
+}
+
+func (m *DownloadManager) notify(d *Download) {
+	if m.OnProgress != nil {
+		m.OnProgress(d)
+	}
+}
+
+// saveBlob prompts the browser to save blob as filename.
+func saveBlob(blob *js.Object, filename string) {
+	url := js.Global.Get("URL").Call("createObjectURL", blob)
+	a := js.Global.Get("document").Call("createElement", "a")
+	a.Set("href", url)
+	a.Set("download", filename)
+	a.Get("style").Set("display", "none")
+	body := js.Global.Get("document").Get("body")
+	body.Call("appendChild", a)
+	a.Call("click")
+	body.Call("removeChild", a)
+	// Revoking immediately can cancel the download in some browsers.
+	js.Global.Call("setTimeout", func() {
+		js.Global.Get("URL").Call("revokeObjectURL", url)
+	}, 1000)
+}
+
+// DownloadOptions configures DownloadToFile.
+type DownloadOptions struct {
+	// Client sends the request. It defaults to the zero Client.
+	Client *Client
+
+	// Method defaults to "GET". Data is the optional request body, for
+	// example for exports generated from a POSTed query.
+	Method string
+	Data   interface{}
+
+	// OnProgress is called as the file is downloaded. It is called from
+	// an event listener and must not block.
+	OnProgress func(ProgressEvent)
+}
+
+// DownloadToFile fetches url and prompts the browser to save the
+// response as filename, which is the "export CSV" flow of many web apps.
+// If filename is empty, it is taken from the response's
+// Content-Disposition header or else the last segment of the url. opts
+// may be nil.
+//
+// A status code other than 2xx is returned as a *StatusError.
+func DownloadToFile(ctx context.Context, url, filename string, opts *DownloadOptions) error {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	c := opts.Client
+	if c == nil {
+		c = &Client{}
+	}
+	method := opts.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	req := c.NewRequest(method, url)
+	req.ResponseType = Blob
+	if opts.OnProgress != nil {
+		req.OnProgress(opts.OnProgress)
+	}
+	if err := c.Do(ctx, req, opts.Data); err != nil {
+		return err
+	}
+	if !req.IsStatus2xx() {
+		return NewStatusError(req)
+	}
+
+	if filename == "" {
+		filename = downloadFilename(req)
+	}
+	saveBlob(req.Response, filename)
+	return nil
+}
+
+// downloadFilename returns the filename suggested by the response of
+// req.
+func downloadFilename(req *Request) string {
+	if _, params, err := mime.ParseMediaType(req.ResponseHeader("Content-Disposition")); err == nil && params["filename"] != "" {
+		return path.Base(params["filename"])
+	}
+	if u, err := url.Parse(req.URL()); err == nil {
+		if name := path.Base(u.Path); name != "/" && name != "." {
+			return name
+		}
+	}
+	return "download"
+}
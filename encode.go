@@ -0,0 +1,126 @@
+package xhr
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gopherjs/gopherjs/js"
+	"github.com/rocketlaunchr/react/forks/encoding/json"
+)
+
+// BodyMarshaler is implemented by types that encode themselves into a
+// request body. body can be any type accepted by Send other than a
+// BodyMarshaler. contentType is set as the "Content-Type" of the request
+// unless one has been set already.
+type BodyMarshaler interface {
+	Marshal() (body interface{}, contentType string, err error)
+}
+
+// EncoderFunc encodes v into a request body.
+type EncoderFunc func(v interface{}) ([]byte, error)
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]EncoderFunc{
+		ApplicationJSON:   json.Marshal,
+		"application/xml": xml.Marshal,
+		"text/xml":        xml.Marshal,
+		ApplicationForm:   encodeForm,
+		ApplicationGob:    EncodeGob,
+	}
+)
+
+// RegisterEncoder registers fn as the encoder used by Send for request
+// bodies of mediaType, such as "application/msgpack". It replaces any
+// encoder already registered for mediaType.
+func RegisterEncoder(mediaType string, fn EncoderFunc) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	encoders[strings.ToLower(mediaType)] = fn
+}
+
+// encodeBody converts data passed to Send into a value accepted by
+// XMLHttpRequest. Values that XMLHttpRequest accepts natively are
+// returned as they are. io.Readers are read into a Blob. BodyMarshalers
+// encode themselves, and other Go values are encoded by the encoder
+// registered for the request's "Content-Type", which defaults to
+// ApplicationJSON.
+func (r *Request) encodeBody(data interface{}) (interface{}, error) {
+	switch d := data.(type) {
+	case nil, string, []byte, *js.Object:
+		return data, nil
+	case *BlobObject:
+		return d.Object, nil
+	case *FileObject:
+		return d.Object, nil
+	case BodyMarshaler:
+		body, contentType, err := d.Marshal()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := body.(BodyMarshaler); ok {
+			return nil, fmt.Errorf("xhr: %T.Marshal returned a BodyMarshaler", data)
+		}
+		if contentType != "" && r.header.Get("Content-Type") == "" {
+			r.SetRequestHeader("Content-Type", contentType)
+		}
+		return r.encodeBody(body)
+	case io.Reader:
+		return readerBlob(d)
+	case interface{ Underlying() *js.Object }:
+		return d.Underlying(), nil
+	}
+	if isJSWrapper(data) {
+		return data, nil
+	}
+
+	contentType := r.header.Get("Content-Type")
+	mediaType := ApplicationJSON
+	if contentType != "" {
+		mt, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnsupportedMediaType, err)
+		}
+		mediaType = mt
+	}
+
+	encodersMu.RLock()
+	fn := encoders[mediaType]
+	encodersMu.RUnlock()
+
+	if fn == nil {
+		switch {
+		case strings.HasSuffix(mediaType, "+json"):
+			fn = json.Marshal
+		case strings.HasSuffix(mediaType, "+xml"):
+			fn = xml.Marshal
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+		}
+	}
+
+	b, err := fn(data)
+	if err != nil {
+		return nil, err
+	}
+	if contentType == "" {
+		r.SetRequestHeader("Content-Type", mediaType)
+	}
+	return b, nil
+}
+
+// isJSWrapper reports whether v is a pointer to a struct embedding
+// *js.Object as its first field, such as *Params, which GopherJS passes
+// to JavaScript as the embedded object.
+func isJSWrapper(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct || t.Elem().NumField() == 0 {
+		return false
+	}
+	return t.Elem().Field(0).Type == jsObjectType
+}
@@ -0,0 +1,125 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrConflict is matched by a *ConflictError with errors.Is.
+var ErrConflict = errors.New("conflict: resource was modified")
+
+// ConflictError is returned when a conditional update is rejected with
+// 412 Precondition Failed because the resource changed since it was
+// fetched. It carries the latest representation so the user can merge
+// or retry.
+type ConflictError struct {
+	// Latest is the completed request fetching the current
+	// representation. It is nil if the refetch failed.
+	Latest *Request
+
+	// ETag of the current representation.
+	ETag string
+}
+
+func (e *ConflictError) Error() string {
+	return ErrConflict.Error()
+}
+
+// Is reports whether target is ErrConflict.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// ETagTracker implements optimistic concurrency control. It remembers
+// the ETag of every resource fetched through its Middleware and sends it
+// as If-Match on subsequent PUT, PATCH and DELETE requests of the same
+// url.
+type ETagTracker struct {
+	// Client is used to refetch a resource after a conflict.
+	Client *Client
+
+	mu    sync.Mutex
+	etags map[string]string
+}
+
+// NewETagTracker returns an ETagTracker for c. Its Middleware should be
+// added to c.
+func NewETagTracker(c *Client) *ETagTracker {
+	return &ETagTracker{Client: c, etags: map[string]string{}}
+}
+
+// ETag returns the remembered ETag for url.
+func (t *ETagTracker) ETag(url string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.etags[url]
+}
+
+// Forget removes the remembered ETag for url.
+func (t *ETagTracker) Forget(url string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.etags, url)
+}
+
+// Middleware returns the Middleware that attaches If-Match headers and
+// converts 412 responses into a *ConflictError.
+func (t *ETagTracker) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request, data interface{}) error {
+			conditional := false
+			switch req.Method() {
+			case "PUT", "PATCH", "DELETE":
+				if etag := t.ETag(req.URL()); etag != "" {
+					req.SetRequestHeader("If-Match", etag)
+					conditional = true
+				}
+			}
+
+			err := next(ctx, req, data)
+			if req.Status == 412 && conditional {
+				// Also when FailOnError turned the 412 into a *StatusError.
+				return t.conflict(ctx, next, req)
+			}
+			if err != nil {
+				return err
+			}
+
+			if req.IsStatus2xx() {
+				if etag := req.ResponseHeader("ETag"); etag != "" {
+					t.set(req.URL(), etag)
+				} else if req.Method() == "DELETE" {
+					t.Forget(req.URL())
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// conflict refetches the resource of req, whose update was rejected with
+// 412 Precondition Failed, and returns the *ConflictError.
+func (t *ETagTracker) conflict(ctx context.Context, next Handler, req *Request) error {
+	// req.URL has been resolved against the client's BaseURL already.
+	var latest *Request
+	if t.Client != nil {
+		latest = t.Client.newRequest("GET", req.URL())
+	} else {
+		latest = NewRequest("GET", req.URL())
+	}
+	if next(ctx, latest, nil) != nil || !latest.IsStatus2xx() {
+		return &ConflictError{}
+	}
+	etag := latest.ResponseHeader("ETag")
+	if etag != "" {
+		t.set(req.URL(), etag)
+	}
+	return &ConflictError{Latest: latest, ETag: etag}
+}
+
+func (t *ETagTracker) set(url, etag string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.etags[url] = etag
+}
@@ -0,0 +1,239 @@
+package xhr
+
+import (
+	"sync"
+
+	"github.com/gopherjs/gopherjs/js"
+	"honnef.co/go/js/util"
+)
+
+// Listener is a handle to a registered event listener.
+type Listener struct {
+	set *listenerSet
+	typ string
+	fn  func(*js.Object)
+}
+
+// Remove detaches the listener. It is safe to call Remove more than once.
+func (l *Listener) Remove() {
+	l.set.remove(l)
+}
+
+// listenerSet tracks the listeners registered on an EventTarget, so that
+// they can be detached together.
+type listenerSet struct {
+	target util.EventTarget
+
+	mu   sync.Mutex
+	list []*Listener
+}
+
+func (s *listenerSet) add(typ string, fn func(*js.Object)) *Listener {
+	l := &Listener{set: s, typ: typ, fn: fn}
+	s.target.AddEventListener(typ, false, fn)
+
+	s.mu.Lock()
+	s.list = append(s.list, l)
+	s.mu.Unlock()
+	return l
+}
+
+func (s *listenerSet) remove(l *Listener) {
+	s.mu.Lock()
+	found := false
+	for i, x := range s.list {
+		if x == l {
+			s.list = append(s.list[:i], s.list[i+1:]...)
+			found = true
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if found {
+		s.target.RemoveEventListener(l.typ, false, l.fn)
+	}
+}
+
+func (s *listenerSet) len() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.list)
+}
+
+func (s *listenerSet) removeAll() {
+	s.mu.Lock()
+	list := s.list
+	s.list = nil
+	s.mu.Unlock()
+
+	for _, l := range list {
+		s.target.RemoveEventListener(l.typ, false, l.fn)
+	}
+}
+
+// Listen registers fn for events of type typ and returns a handle that
+// can detach it again. Unlike listeners added with AddEventListener, it
+// is also detached by RemoveAllListeners.
+func (r *Request) Listen(typ string, fn func(*js.Object)) *Listener {
+	return r.listeners.add(typ, fn)
+}
+
+// RemoveAllListeners detaches all listeners registered with Listen and
+// the On* methods of the request and its Upload, so that long-lived pages
+// don't retain their closures.
+func (r *Request) RemoveAllListeners() {
+	r.listeners.removeAll()
+	if r.upload != nil {
+		r.upload.RemoveAllListeners()
+	}
+}
+
+// ActiveListeners returns the number of listeners attached to the
+// request and its Upload, excluding those added with AddEventListener.
+// This includes the listeners used internally by an in-flight Send,
+// which are detached once Send returns. It allows tests to verify that
+// requests don't leak listeners.
+func (r *Request) ActiveListeners() int {
+	n := r.listeners.len() + r.sendListeners.len()
+	if r.upload != nil {
+		n += r.upload.listeners.len()
+	}
+	return n
+}
+
+// Listen registers fn for events of type typ and returns a handle that
+// can detach it again. Unlike listeners added with AddEventListener, it
+// is also detached by RemoveAllListeners.
+func (u *Upload) Listen(typ string, fn func(*js.Object)) *Listener {
+	return u.listeners.add(typ, fn)
+}
+
+// RemoveAllListeners detaches all listeners registered with Listen and
+// the On* methods.
+func (u *Upload) RemoveAllListeners() {
+	u.listeners.removeAll()
+}
+
+// ProgressEvent holds the data of a JavaScript ProgressEvent.
+type ProgressEvent struct {
+	// Loaded is the number of bytes transferred so far.
+	Loaded int64
+
+	// Total is the total number of bytes to transfer. It is only
+	// meaningful if LengthComputable is true.
+	Total int64
+
+	LengthComputable bool
+}
+
+// Percent returns the percentage of bytes transferred so far, between 0
+// and 100, or -1 if the total is unknown.
+func (e ProgressEvent) Percent() float64 {
+	if !e.LengthComputable || e.Total <= 0 {
+		return -1
+	}
+	p := float64(e.Loaded) / float64(e.Total) * 100
+	if p > 100 {
+		p = 100 // Loaded may count decoded bytes
+	}
+	return p
+}
+
+func listenProgress(s *listenerSet, typ string, fn func(ProgressEvent)) *Listener {
+	return s.add(typ, func(e *js.Object) {
+		fn(ProgressEvent{
+			Loaded:           e.Get("loaded").Int64(),
+			Total:            e.Get("total").Int64(),
+			LengthComputable: e.Get("lengthComputable").Bool(),
+		})
+	})
+}
+
+// OnLoad registers fn to be called when the request completes
+// successfully. fn is called from an event listener and must not block.
+func (r *Request) OnLoad(fn func(ProgressEvent)) *Listener {
+	return listenProgress(r.listeners, "load", fn)
+}
+
+// OnError registers fn to be called when the request fails. fn is called
+// from an event listener and must not block.
+func (r *Request) OnError(fn func(ProgressEvent)) *Listener {
+	return listenProgress(r.listeners, "error", fn)
+}
+
+// OnAbort registers fn to be called when the request is aborted. fn is
+// called from an event listener and must not block.
+func (r *Request) OnAbort(fn func(ProgressEvent)) *Listener {
+	return listenProgress(r.listeners, "abort", fn)
+}
+
+// OnTimeout registers fn to be called when the request times out. fn is
+// called from an event listener and must not block.
+func (r *Request) OnTimeout(fn func(ProgressEvent)) *Listener {
+	return listenProgress(r.listeners, "timeout", fn)
+}
+
+// OnLoadEnd registers fn to be called when the request has finished,
+// whether successfully or not. fn is called from an event listener and
+// must not block.
+func (r *Request) OnLoadEnd(fn func(ProgressEvent)) *Listener {
+	return listenProgress(r.listeners, "loadend", fn)
+}
+
+// OnProgress registers fn to be called periodically while the response
+// is downloaded. fn is called from an event listener and must not block.
+//
+// If the browser doesn't report the total size, it is taken from the
+// response's "Content-Length" when available, so that Percent works.
+func (r *Request) OnProgress(fn func(ProgressEvent)) *Listener {
+	return listenProgress(r.listeners, "progress", func(e ProgressEvent) {
+		if !e.LengthComputable {
+			if n := r.ContentLength(); n >= 0 {
+				e.Total, e.LengthComputable = n, true
+			}
+		}
+		fn(e)
+	})
+}
+
+// OnLoad registers fn to be called when the upload completes
+// successfully. fn is called from an event listener and must not block.
+func (u *Upload) OnLoad(fn func(ProgressEvent)) *Listener {
+	return listenProgress(u.listeners, "load", fn)
+}
+
+// OnError registers fn to be called when the upload fails. fn is called
+// from an event listener and must not block.
+func (u *Upload) OnError(fn func(ProgressEvent)) *Listener {
+	return listenProgress(u.listeners, "error", fn)
+}
+
+// OnAbort registers fn to be called when the upload is aborted. fn is
+// called from an event listener and must not block.
+func (u *Upload) OnAbort(fn func(ProgressEvent)) *Listener {
+	return listenProgress(u.listeners, "abort", fn)
+}
+
+// OnTimeout registers fn to be called when the upload times out. fn is
+// called from an event listener and must not block.
+func (u *Upload) OnTimeout(fn func(ProgressEvent)) *Listener {
+	return listenProgress(u.listeners, "timeout", fn)
+}
+
+// OnLoadEnd registers fn to be called when the upload has finished,
+// whether successfully or not. fn is called from an event listener and
+// must not block.
+func (u *Upload) OnLoadEnd(fn func(ProgressEvent)) *Listener {
+	return listenProgress(u.listeners, "loadend", fn)
+}
+
+// OnProgress registers fn to be called periodically while the request
+// body is uploaded. fn is called from an event listener and must not
+// block.
+func (u *Upload) OnProgress(fn func(ProgressEvent)) *Listener {
+	return listenProgress(u.listeners, "progress", fn)
+}
@@ -0,0 +1,96 @@
+package xhr
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// The errors returned by Send when it failed without a response. Both
+// wrap ErrFailure, so errors.Is(err, ErrFailure) matches either.
+var (
+	// ErrNetwork is returned when the request failed at the network
+	// layer, for example because the browser is offline or the server
+	// is unreachable.
+	ErrNetwork = fmt.Errorf("network error: %w", ErrFailure)
+
+	// ErrCORSBlocked is returned when the request was most likely
+	// blocked by the browser's CORS policy. The XHR API doesn't expose
+	// the reason for a failure, so this is a best effort guess: the
+	// request was cross-origin, the browser reports being online and no
+	// response url is available. Network failures of cross-origin
+	// requests, such as an unreachable server, look the same and are
+	// also reported as ErrCORSBlocked.
+	ErrCORSBlocked = fmt.Errorf("blocked by CORS policy: %w", ErrFailure)
+)
+
+// TimeoutError is the error returned by Send when the request timed out,
+// whether due to Timeout, HeadersTimeout or the context's deadline. It
+// reports how far the request got, to aid debugging flaky networks.
+//
+// TimeoutError wraps ErrTimeout, ErrHeadersTimeout or
+// context.DeadlineExceeded, so errors.Is matches these errors.
+type TimeoutError struct {
+	Err error
+
+	// Elapsed is the time from sending the request until it timed out.
+	Elapsed time.Duration
+
+	// Loaded is the number of response bytes received.
+	Loaded int64
+
+	// Uploaded is the number of request bytes sent. Listening to upload
+	// progress forces a CORS preflight, so it is only tracked if
+	// listeners were registered on the request's Upload.
+	Uploaded int64
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("%v after %v (%d bytes received, %d bytes sent)", e.Err, e.Elapsed, e.Loaded, e.Uploaded)
+}
+
+// Unwrap returns e.Err.
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout returns true. It allows the error to be detected like a
+// net.Error.
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// classifyFailure returns the error for a request that completed with a
+// status of 0 without being aborted or timing out. The classification is
+// best effort, see ErrCORSBlocked.
+func (r *Request) classifyFailure() error {
+	if nav := js.Global.Get("navigator"); nav != js.Undefined {
+		if online := nav.Get("onLine"); online != js.Undefined && !online.Bool() {
+			return ErrNetwork
+		}
+	}
+	if r.Get("responseURL").String() == "" && isCrossOrigin(r.url) {
+		return ErrCORSBlocked
+	}
+	return ErrNetwork
+}
+
+// isCrossOrigin reports whether rawURL refers to a different origin than
+// the current page.
+func isCrossOrigin(rawURL string) bool {
+	href := locationHref()
+	if href == "" {
+		return false
+	}
+	page, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+	target, err := url.Parse(resolveURL(href, rawURL))
+	if err != nil {
+		return false
+	}
+	return page.Scheme != target.Scheme || page.Host != target.Host
+}
@@ -0,0 +1,139 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rocketlaunchr/react/forks/encoding/json"
+)
+
+var (
+	// ErrFirebaseCanceled is returned by ListenFirebase when the server
+	// cancels the listener, usually because security rules no longer
+	// permit reading the location.
+	ErrFirebaseCanceled = errors.New("firebase: listen canceled")
+	// ErrFirebaseAuthRevoked is returned by ListenFirebase when the auth
+	// token expired. Listen again with a fresh token.
+	ErrFirebaseAuthRevoked = errors.New("firebase: auth revoked")
+
+	// errFirebaseRestart stops a stream that has grown too large.
+	errFirebaseRestart = errors.New("firebase: restart stream")
+)
+
+// firebaseMaxStream is the number of bytes after which ListenFirebase
+// restarts the stream, since the browser retains the whole response text
+// of a request until it is done.
+const firebaseMaxStream = 8 << 20
+
+// FirebaseEvent is a change of a Firebase Realtime Database location.
+type FirebaseEvent struct {
+	// Type is "put" when the data at Path was replaced and "patch" when
+	// the children of Path were updated.
+	Type string
+
+	// Path is relative to the listened location.
+	Path string
+
+	Data json.RawMessage
+}
+
+// ListenFirebase listens to changes of the Firebase Realtime Database
+// location at url, such as "https://app.firebaseio.com/items.json?auth=TOKEN",
+// using the REST streaming protocol, and sends them on events. This
+// allows GopherJS apps to use the Realtime Database without the
+// JavaScript SDK. Cloud Firestore uses a different protocol for its
+// listeners and is not supported.
+//
+// The first event is a "put" with the current data. ListenFirebase
+// reconnects when the server closes the stream and only returns when ctx
+// is done or an error occurs. events is never closed.
+//
+// Long-lived streams are restarted once they have received several
+// megabytes, so that the response text retained by the browser doesn't
+// grow without bound. Like after every reconnect, the first event of the
+// new stream is a "put" with the current data.
+func ListenFirebase(ctx context.Context, c *Client, url string, events chan<- FirebaseEvent) error {
+	if c == nil {
+		c = &Client{}
+	}
+
+	for {
+		err := listenFirebase(ctx, c, url, events)
+		if err == errFirebaseRestart {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func listenFirebase(ctx context.Context, c *Client, url string, events chan<- FirebaseEvent) error {
+	req := c.NewRequest("GET", url)
+	req.SetRequestHeader("Accept", "text/event-stream")
+
+	var (
+		buf      string // Unparsed remainder of the stream
+		received int
+	)
+	err := c.DoStreaming(ctx, req, nil, func(chunk string) error {
+		received += len(chunk)
+		buf += strings.Replace(chunk, "\r\n", "\n", -1)
+		for {
+			i := strings.Index(buf, "\n\n")
+			if i < 0 {
+				if received >= firebaseMaxStream && buf == "" {
+					return errFirebaseRestart // Between events, so none are lost
+				}
+				return nil
+			}
+			block := buf[:i]
+			buf = buf[i+2:]
+
+			var typ, data string
+			for _, line := range strings.Split(block, "\n") {
+				switch {
+				case strings.HasPrefix(line, "event:"):
+					typ = strings.TrimSpace(line[len("event:"):])
+				case strings.HasPrefix(line, "data:"):
+					data += strings.TrimSpace(line[len("data:"):])
+				}
+			}
+
+			switch typ {
+			case "put", "patch":
+				var payload struct {
+					Path string          `json:"path"`
+					Data json.RawMessage `json:"data"`
+				}
+				if err := json.Unmarshal([]byte(data), &payload); err != nil {
+					return err
+				}
+				select {
+				case events <- FirebaseEvent{Type: typ, Path: payload.Path, Data: payload.Data}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			case "cancel":
+				return ErrFirebaseCanceled
+			case "auth_revoked":
+				return ErrFirebaseAuthRevoked
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if !req.IsStatus2xx() {
+		return NewStatusError(req)
+	}
+	return nil
+}
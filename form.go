@@ -0,0 +1,82 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// underlying returns the *js.Object backing v. v can be a *js.Object or
+// any value with an Underlying method, such as the types found in
+// honnef.co/go/js/dom.
+func underlying(v interface{}) *js.Object {
+	switch v := v.(type) {
+	case *js.Object:
+		return v
+	case interface{ Underlying() *js.Object }:
+		return v.Underlying()
+	}
+	return nil
+}
+
+// SendForm submits an HTML form element in the background. The form's
+// method, action and enctype attributes are respected, so existing forms
+// can be progressively enhanced without duplicating their configuration.
+//
+// form can be a *js.Object or a js/dom element such as
+// *dom.HTMLFormElement.
+func SendForm(ctx context.Context, form interface{}) (*Response, error) {
+	o := underlying(form)
+	if o == nil {
+		return nil, errors.New("form must be a *js.Object or js/dom element")
+	}
+
+	method := strings.ToUpper(o.Get("method").String())
+	action := o.Get("action").String()
+	fd := js.Global.Get("FormData").New(o)
+
+	var data interface{}
+	contentType := ""
+
+	if method == "GET" {
+		// Like the browser, replace the action's query string with the form values.
+		u := js.Global.Get("URL").New(action)
+		u.Set("search", js.Global.Get("URLSearchParams").New(fd).Call("toString"))
+		action = u.Get("href").String()
+	} else {
+		switch o.Get("enctype").String() {
+		case "multipart/form-data":
+			data = fd // The browser sets Content-Type with the boundary
+		case TextPlain:
+			var sb strings.Builder
+			entries := fd.Call("entries")
+			for {
+				next := entries.Call("next")
+				if next.Get("done").Bool() {
+					break
+				}
+				kv := next.Get("value")
+				sb.WriteString(kv.Index(0).String() + "=" + kv.Index(1).String() + "\r\n")
+			}
+			data = sb.String()
+			contentType = TextPlain
+		default:
+			data = js.Global.Get("URLSearchParams").New(fd).Call("toString").String()
+			contentType = ApplicationForm
+		}
+	}
+
+	req := NewRequest(method, action)
+	req.ResponseType = Text
+	if contentType != "" {
+		req.SetRequestHeader("Content-Type", contentType)
+	}
+
+	err := req.Send(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(req), nil
+}
@@ -0,0 +1,208 @@
+package xhr
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+var (
+	jsObjectType      = reflect.TypeOf((*js.Object)(nil))
+	blobObjectType    = reflect.TypeOf((*BlobObject)(nil))
+	byteSliceType     = reflect.TypeOf([]byte(nil))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// FormDataFromStruct creates a FormData object from the fields of the
+// struct, or pointer to struct, v. It can be passed to Send as a
+// multipart/form-data body.
+//
+// Fields are named by their `form:"name"` tag, or else the field name.
+// The tag option "omitempty" skips fields with zero values, and a tag of
+// "-" skips the field. Embedded structs are flattened.
+//
+// Strings, booleans, numbers and types implementing
+// encoding.TextMarshaler are appended as text. []byte fields are
+// appended as Blobs, and *BlobObject and *js.Object fields, such as
+// Files, as they are. Slices and arrays of these types append one entry
+// per element. Nil pointers are skipped.
+func FormDataFromStruct(v interface{}) (*js.Object, error) {
+	fd := js.Global.Get("FormData").New()
+	err := walkForm(v, func(name string, f reflect.Value) error {
+		switch f.Type() {
+		case jsObjectType:
+			fd.Call("append", name, f.Interface())
+			return nil
+		case blobObjectType:
+			fd.Call("append", name, f.Interface().(*BlobObject).Object)
+			return nil
+		case byteSliceType:
+			fd.Call("append", name, js.Global.Get("Blob").New([]interface{}{f.Bytes()}))
+			return nil
+		}
+		s, err := formString(f)
+		if err != nil {
+			return err
+		}
+		fd.Call("append", name, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fd, nil
+}
+
+// walkForm calls fn for every value of the fields of the struct v, as
+// described by FormDataFromStruct. Slices and arrays result in one call
+// per element, except for []byte.
+func walkForm(v interface{}, fn func(name string, f reflect.Value) error) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("xhr: cannot encode %T as form", v)
+	}
+	return walkFormStruct(rv, fn)
+}
+
+func walkFormStruct(rv reflect.Value, fn func(name string, f reflect.Value) error) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("form")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		f := rv.Field(i)
+
+		if sf.Anonymous && name == "" {
+			for f.Kind() == reflect.Ptr && !f.IsNil() {
+				f = f.Elem()
+			}
+			if f.Kind() == reflect.Struct {
+				if err := walkFormStruct(f, fn); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if strings.Contains(","+opts+",", ",omitempty,") && f.IsZero() {
+			continue
+		}
+		if err := walkFormValue(name, f, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkFormValue(name string, f reflect.Value, fn func(name string, f reflect.Value) error) error {
+	switch f.Type() {
+	case jsObjectType, blobObjectType:
+		if f.IsNil() {
+			return nil
+		}
+		return fn(name, f)
+	case byteSliceType:
+		return fn(name, f)
+	}
+	if f.Type().Implements(textMarshalerType) {
+		if f.Kind() == reflect.Ptr && f.IsNil() {
+			return nil
+		}
+		return fn(name, f)
+	}
+
+	switch f.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if f.IsNil() {
+			return nil
+		}
+		return walkFormValue(name, f.Elem(), fn)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < f.Len(); i++ {
+			if err := walkFormValue(name, f.Index(i), fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fn(name, f)
+}
+
+// formString formats a field value as form text.
+func formString(f reflect.Value) (string, error) {
+	if f.Type().Implements(textMarshalerType) {
+		b, err := f.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	switch f.Kind() {
+	case reflect.String:
+		return f.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(f.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(f.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(f.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(f.Float(), 'g', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(f.Float(), 'g', -1, 64), nil
+	}
+	return "", fmt.Errorf("xhr: cannot encode %s as form value", f.Type())
+}
+
+// EncodeForm encodes the fields of the struct, or pointer to struct, v
+// as an application/x-www-form-urlencoded body and returns it with its
+// "Content-Type". Fields are named and formatted as by
+// FormDataFromStruct, except that []byte fields are encoded as text.
+//
+// EncodeForm panics if v is not a struct or has fields that can't be
+// encoded as text, such as Blobs.
+func EncodeForm(v interface{}) ([]byte, string) {
+	b, err := encodeForm(v)
+	if err != nil {
+		panic(err)
+	}
+	return b, ApplicationForm
+}
+
+// encodeForm is like EncodeForm, but returns errors. v may also be a
+// url.Values.
+func encodeForm(v interface{}) ([]byte, error) {
+	if vals, ok := v.(url.Values); ok {
+		return []byte(vals.Encode()), nil
+	}
+	vals := url.Values{}
+	err := walkForm(v, func(name string, f reflect.Value) error {
+		if f.Type() == byteSliceType {
+			vals.Add(name, string(f.Bytes()))
+			return nil
+		}
+		s, err := formString(f)
+		if err != nil {
+			return err
+		}
+		vals.Add(name, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []byte(vals.Encode()), nil
+}
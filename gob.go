@@ -0,0 +1,59 @@
+package xhr
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+)
+
+// ApplicationGob is the "Content-Type" used for encoding/gob bodies. It is
+// useful when the backend is also written in Go.
+const ApplicationGob = "application/x-gob"
+
+// EncodeGob encodes v into a request body for use with ApplicationGob.
+func EncodeGob(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Gob decodes a gob-encoded response into v. The request's ResponseType
+// must be ArrayBuffer.
+func (r *Request) Gob(v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(r.ResponseBytes())).Decode(v)
+}
+
+// SendGob gob-encodes in, sends it and decodes the response into out.
+// in and out may be nil when there is no request or response body.
+//
+// Unlike Send, a status code other than 2xx is treated as an error.
+func SendGob(ctx context.Context, method, url string, in, out interface{}) error {
+	req := NewRequest(method, url)
+	req.ResponseType = ArrayBuffer
+	req.SetRequestHeader("Accept", ApplicationGob)
+
+	var data interface{}
+	if in != nil {
+		b, err := EncodeGob(in)
+		if err != nil {
+			return err
+		}
+		data = b
+		req.SetRequestHeader("Content-Type", ApplicationGob)
+	}
+
+	err := req.Send(ctx, data)
+	if err != nil {
+		return err
+	}
+	if !req.IsStatus2xx() {
+		return NewStatusError(req)
+	}
+	if out == nil {
+		return nil
+	}
+	return req.Gob(out)
+}
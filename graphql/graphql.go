@@ -0,0 +1,228 @@
+// Package graphql provides a GraphQL client built on package xhr.
+//
+// Queries and mutations are sent through an xhr.Client, so its headers
+// and middleware (such as authentication) apply. Automatic Persisted
+// Queries and response caching are optional.
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+
+	"github.com/rocketlaunchr/react/forks/encoding/json"
+
+	xhr "github.com/rocketlaunchr/gopherjs-xhr"
+)
+
+// Location is the position in the query an Error refers to.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is an entry of the errors array of a GraphQL response.
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
+
+// Code returns extensions.code, which many servers use to classify
+// errors.
+func (e Error) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// Errors is returned when a response contains errors. The data that was
+// received is still decoded into the result.
+type Errors []Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Message)
+	}
+	return "graphql: " + strings.Join(msgs, "; ")
+}
+
+// Client executes GraphQL operations.
+type Client struct {
+	// Client prepares and sends the underlying requests, applying its
+	// headers and middleware.
+	*xhr.Client
+
+	// Endpoint is the url of the GraphQL endpoint. It is resolved
+	// against the Client's BaseURL.
+	Endpoint string
+
+	// PersistedQueries enables Automatic Persisted Queries. Only the
+	// hash of a query is sent until the server reports that it does not
+	// know it.
+	PersistedQueries bool
+
+	// Cache, if not nil, caches the results of queries. Mutations are
+	// never cached.
+	Cache *Cache
+}
+
+// NewClient returns a Client for the GraphQL endpoint at url.
+func NewClient(url string) *Client {
+	return &Client{Client: &xhr.Client{}, Endpoint: url}
+}
+
+// Query executes a query and decodes its data into out.
+func (c *Client) Query(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	var key string
+	if c.Cache != nil {
+		vars, err := json.Marshal(variables)
+		if err != nil {
+			return err
+		}
+		key = query + "\x00" + string(vars)
+		if data, ok := c.Cache.get(key); ok {
+			return decodeData(data, out)
+		}
+	}
+
+	data, err := c.execute(ctx, query, variables, out)
+	if err == nil && c.Cache != nil {
+		c.Cache.set(key, data)
+	}
+	return err
+}
+
+// Mutate executes a mutation and decodes its data into out.
+func (c *Client) Mutate(ctx context.Context, mutation string, variables map[string]interface{}, out interface{}) error {
+	_, err := c.execute(ctx, mutation, variables, out)
+	return err
+}
+
+type request struct {
+	Query      string                 `json:"query,omitempty"`
+	Variables  map[string]interface{} `json:"variables,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors Errors          `json:"errors"`
+}
+
+func (c *Client) execute(ctx context.Context, query string, variables map[string]interface{}, out interface{}) (json.RawMessage, error) {
+	body := request{Query: query, Variables: variables}
+
+	if c.PersistedQueries {
+		sum := sha256.Sum256([]byte(query))
+		body.Extensions = map[string]interface{}{
+			"persistedQuery": map[string]interface{}{"version": 1, "sha256Hash": hex.EncodeToString(sum[:])},
+		}
+		body.Query = ""
+
+		resp, err := c.post(ctx, body)
+		if err != nil {
+			return nil, err
+		}
+		if !persistedQueryNotFound(resp.Errors) {
+			return resp.Data, result(resp, out)
+		}
+		body.Query = query // Register the query with the server
+	}
+
+	resp, err := c.post(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data, result(resp, out)
+}
+
+func (c *Client) post(ctx context.Context, body request) (*response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req := c.NewRequest("POST", c.Endpoint)
+	req.ResponseType = xhr.Text
+	req.SetRequestHeader("Content-Type", xhr.ApplicationJSON)
+	req.SetRequestHeader("Accept", xhr.ApplicationJSON)
+
+	err = c.Do(ctx, req, string(b))
+	if err != nil {
+		return nil, err
+	}
+
+	var resp response
+	err = json.Unmarshal(req.ResponseBytes(), &resp)
+	if err != nil {
+		if !req.IsStatus2xx() {
+			return nil, xhr.NewStatusError(req)
+		}
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func result(resp *response, out interface{}) error {
+	if err := decodeData(resp.Data, out); err != nil {
+		return err
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Errors
+	}
+	return nil
+}
+
+func decodeData(data json.RawMessage, out interface{}) error {
+	if out == nil || len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func persistedQueryNotFound(errs Errors) bool {
+	for _, err := range errs {
+		if err.Message == "PersistedQueryNotFound" || err.Code() == "PERSISTED_QUERY_NOT_FOUND" {
+			return true
+		}
+	}
+	return false
+}
+
+// Cache stores query results keyed by query and variables. The zero
+// value is ready to use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]json.RawMessage
+}
+
+// Clear removes all cached results, e.g. after a mutation.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+func (c *Cache) get(key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.entries[key]
+	return data, ok
+}
+
+func (c *Cache) set(key string, data json.RawMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[string]json.RawMessage{}
+	}
+	c.entries[key] = data
+}
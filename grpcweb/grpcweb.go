@@ -0,0 +1,261 @@
+// Package grpcweb implements the gRPC-Web protocol on top of package xhr.
+//
+// Both unary and server-streaming calls are supported, using either the
+// binary "application/grpc-web+proto" or the base64
+// "application/grpc-web-text" format. Messages are passed as serialized
+// protocol buffers, so generated service clients can marshal them with
+// any protobuf library:
+//
+//	c := grpcweb.NewClient("https://api.example.com")
+//	out, _, err := c.Invoke(ctx, "/helloworld.Greeter/SayHello", in, nil)
+package grpcweb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	xhr "github.com/rocketlaunchr/gopherjs-xhr"
+)
+
+// Code is a gRPC status code.
+type Code uint32
+
+// The gRPC status codes.
+const (
+	OK Code = iota
+	Canceled
+	Unknown
+	InvalidArgument
+	DeadlineExceeded
+	NotFound
+	AlreadyExists
+	PermissionDenied
+	ResourceExhausted
+	FailedPrecondition
+	Aborted
+	OutOfRange
+	Unimplemented
+	Internal
+	Unavailable
+	DataLoss
+	Unauthenticated
+)
+
+// Status is the error returned when a call completes with a code other
+// than OK.
+type Status struct {
+	Code    Code
+	Message string
+	Trailer http.Header
+}
+
+func (s *Status) Error() string {
+	return fmt.Sprintf("grpc-web: code = %d desc = %s", s.Code, s.Message)
+}
+
+// Client performs gRPC-Web calls.
+type Client struct {
+	// Client prepares and sends the underlying requests, applying its
+	// headers and middleware. Its BaseURL should be the server's address.
+	*xhr.Client
+
+	// Text selects the base64 "application/grpc-web-text" format, which
+	// works through proxies that mangle binary bodies.
+	Text bool
+}
+
+// NewClient returns a Client for the server at baseURL.
+func NewClient(baseURL string) *Client {
+	return &Client{Client: &xhr.Client{BaseURL: baseURL}}
+}
+
+// Invoke performs a unary call. method is the full method name, such as
+// "/package.Service/Method". md is optional metadata sent as headers.
+func (c *Client) Invoke(ctx context.Context, method string, in []byte, md http.Header) ([]byte, http.Header, error) {
+	var out []byte
+	n := 0
+	trailer, err := c.call(ctx, method, in, md, func(msg []byte) error {
+		out = msg
+		n++
+		return nil
+	})
+	if err != nil {
+		return nil, trailer, err
+	}
+	if n != 1 {
+		return nil, trailer, &Status{Code: Internal, Message: "unary call received " + strconv.Itoa(n) + " messages", Trailer: trailer}
+	}
+	return out, trailer, nil
+}
+
+// Stream performs a server-streaming call. recv is called with each
+// message as it arrives. If recv returns an error, the call is canceled
+// and the error is returned.
+func (c *Client) Stream(ctx context.Context, method string, in []byte, md http.Header, recv func(msg []byte) error) (http.Header, error) {
+	return c.call(ctx, method, in, md, recv)
+}
+
+func (c *Client) call(ctx context.Context, method string, in []byte, md http.Header, recv func([]byte) error) (http.Header, error) {
+	contentType := "application/grpc-web+proto"
+	if c.Text {
+		contentType = "application/grpc-web-text"
+	}
+
+	req := c.NewRequest("POST", method)
+	req.SetRequestHeader("Content-Type", contentType)
+	req.SetRequestHeader("Accept", contentType)
+	req.SetRequestHeader("X-Grpc-Web", "1")
+	for name, values := range md {
+		for _, value := range values {
+			req.SetRequestHeader(name, value)
+		}
+	}
+	if !c.Text {
+		// Makes the binary body readable from responseText while loading.
+		req.OverrideMimeType("text/plain; charset=x-user-defined")
+	}
+
+	body := frame(in)
+	var data interface{} = body
+	if c.Text {
+		data = base64.StdEncoding.EncodeToString(body)
+	}
+
+	p := &parser{text: c.Text}
+	err := c.DoStreaming(ctx, req, data, func(chunk string) error {
+		msgs, err := p.feed(chunk)
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if err := recv(msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p.trailer, status(req, p.trailer)
+}
+
+// status determines the outcome of a completed call. gRPC status is
+// carried in the trailer frame, or in the headers for trailers-only
+// responses.
+func status(req *xhr.Request, trailer http.Header) error {
+	code := trailer.Get("Grpc-Status")
+	msg := trailer.Get("Grpc-Message")
+	if code == "" {
+		code = req.ResponseHeader("Grpc-Status")
+		msg = req.ResponseHeader("Grpc-Message")
+	}
+
+	if code == "" {
+		if req.Status == 200 {
+			return &Status{Code: Internal, Message: "missing grpc-status", Trailer: trailer}
+		}
+		return &Status{Code: httpCode(req.Status), Message: req.StatusText, Trailer: trailer}
+	}
+
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return &Status{Code: Unknown, Message: "invalid grpc-status: " + code, Trailer: trailer}
+	}
+	if Code(n) == OK {
+		return nil
+	}
+	return &Status{Code: Code(n), Message: msg, Trailer: trailer}
+}
+
+// httpCode maps HTTP status codes of responses without gRPC status.
+func httpCode(status int) Code {
+	switch status {
+	case 400:
+		return Internal
+	case 401:
+		return Unauthenticated
+	case 403:
+		return PermissionDenied
+	case 404:
+		return Unimplemented
+	case 429, 502, 503, 504:
+		return Unavailable
+	}
+	return Unknown
+}
+
+// frame wraps msg in a gRPC data frame.
+func frame(msg []byte) []byte {
+	b := make([]byte, 5+len(msg))
+	binary.BigEndian.PutUint32(b[1:5], uint32(len(msg)))
+	copy(b[5:], msg)
+	return b
+}
+
+// parser splits a response body into messages and the trailer.
+type parser struct {
+	text    bool
+	b64     string // base64 characters not yet decoded
+	buf     []byte // bytes not yet forming a complete frame
+	trailer http.Header
+}
+
+func (p *parser) feed(chunk string) ([][]byte, error) {
+	if p.text {
+		// The body may consist of several separately padded segments.
+		p.b64 += chunk
+		n := len(p.b64) / 4 * 4
+		start := 0
+		for i := 0; i < n; i += 4 {
+			if i+4 == n || strings.IndexByte(p.b64[i:i+4], '=') >= 0 {
+				b, err := base64.StdEncoding.DecodeString(p.b64[start : i+4])
+				if err != nil {
+					return nil, err
+				}
+				p.buf = append(p.buf, b...)
+				start = i + 4
+			}
+		}
+		p.b64 = p.b64[n:]
+	} else {
+		// x-user-defined maps bytes 0x80-0xFF to U+F780-U+F7FF.
+		for _, r := range chunk {
+			p.buf = append(p.buf, byte(r))
+		}
+	}
+
+	var msgs [][]byte
+	for len(p.buf) >= 5 {
+		n := int(binary.BigEndian.Uint32(p.buf[1:5]))
+		if len(p.buf) < 5+n {
+			break
+		}
+		flag, payload := p.buf[0], p.buf[5:5+n]
+		p.buf = p.buf[5+n:]
+
+		if flag&0x80 != 0 {
+			p.trailer = parseTrailer(payload)
+		} else {
+			msgs = append(msgs, payload)
+		}
+	}
+	return msgs, nil
+}
+
+func parseTrailer(b []byte) http.Header {
+	h := http.Header{}
+	for _, line := range strings.Split(string(b), "\r\n") {
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		h.Add(strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]))
+	}
+	return h
+}
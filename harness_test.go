@@ -0,0 +1,133 @@
+//go:build js
+
+package xhr
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// The tests and benchmarks run with "gopherjs test" under Node.js, using
+// the xhr2 npm package as XMLHttpRequest, against a local server started
+// with Node's http module.
+//
+// They also run in a browser, or under Node.js against an external
+// server, when the url of a server serving the same endpoints, such as
+// testdata/testserver, is given by the "server" query parameter of the
+// page or the XHR_TEST_SERVER environment variable. They are skipped
+// elsewhere.
+
+var (
+	serverOnce sync.Once
+	serverURL  string
+	serverErr  string
+)
+
+// testServer returns the url of the test server, which serves:
+//
+//	/echo           the request body
+//	/bytes?n=N      N bytes
+//	/slow?ms=N      an empty body after N milliseconds
+//	/status?code=N  an empty body with status code N
+func testServer(tb testing.TB) string {
+	serverOnce.Do(startServer)
+	if serverErr != "" {
+		tb.Skip(serverErr)
+	}
+	return serverURL
+}
+
+func startServer() {
+	external := externalServer()
+	node := js.Module != nil && js.Module != js.Undefined && js.Module.Get("require") != js.Undefined
+	if external == "" && !node {
+		serverErr = "requires Node.js or a test server url"
+		return
+	}
+
+	require := func(name string) *js.Object {
+		return js.Module.Call("require", name)
+	}
+	if js.Global.Get("XMLHttpRequest") == js.Undefined {
+		if !node || catch(func() { js.Global.Set("XMLHttpRequest", require("xhr2")) }) != nil {
+			serverErr = "requires the xhr2 npm package"
+			return
+		}
+	}
+	if external != "" {
+		serverURL = external
+		return
+	}
+
+	server := require("http").Call("createServer", func(req, res *js.Object) {
+		u := js.Global.Get("URL").New(req.Get("url"), "http://localhost")
+		query := func(key string) int {
+			n, _ := strconv.Atoi(u.Get("searchParams").Call("get", key).String())
+			return n
+		}
+
+		switch u.Get("pathname").String() {
+		case "/echo":
+			chunks := js.Global.Get("Array").New()
+			req.Call("on", "data", func(chunk *js.Object) {
+				chunks.Call("push", chunk)
+			})
+			req.Call("on", "end", func() {
+				res.Call("end", js.Global.Get("Buffer").Call("concat", chunks))
+			})
+		case "/bytes":
+			res.Call("end", js.Global.Get("Buffer").Call("alloc", query("n"), 'a'))
+		case "/slow":
+			js.Global.Call("setTimeout", func() {
+				res.Call("end")
+			}, query("ms"))
+		case "/status":
+			res.Set("statusCode", query("code"))
+			res.Call("end")
+		default:
+			res.Set("statusCode", 404)
+			res.Call("end")
+		}
+	})
+
+	ready := make(chan struct{})
+	server.Call("listen", 0, "127.0.0.1", func() {
+		close(ready)
+	})
+	<-ready
+	server.Call("unref") // Don't keep Node running once the tests are done
+	serverURL = "http://127.0.0.1:" + strconv.Itoa(server.Call("address").Get("port").Int())
+}
+
+// externalServer returns the url of the test server given by the
+// "server" query parameter of the page or the XHR_TEST_SERVER environment
+// variable, if any.
+func externalServer() string {
+	if loc := js.Global.Get("location"); loc != js.Undefined {
+		if u := js.Global.Get("URLSearchParams").New(loc.Get("search")).Call("get", "server"); u != nil {
+			return strings.TrimSuffix(u.String(), "/")
+		}
+	}
+	return strings.TrimSuffix(os.Getenv("XHR_TEST_SERVER"), "/")
+}
+
+// checkGoroutines fails tb if more goroutines are running than before,
+// after giving goroutines that are about to exit time to do so.
+func checkGoroutines(tb testing.TB, before int) {
+	tb.Helper()
+	var n int
+	for i := 0; i < 20; i++ {
+		if n = runtime.NumGoroutine(); n <= before {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	tb.Errorf("%d goroutines leaked", n-before)
+}
@@ -0,0 +1,199 @@
+package xhr
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// HeadersFromJS converts headers from a JavaScript configuration object
+// into an http.Header. Keys are canonicalized. Array values are added as
+// multiple values and other values are formatted as strings.
+func HeadersFromJS(m js.M) http.Header {
+	h := http.Header{}
+	for name, val := range m {
+		switch val := val.(type) {
+		case []interface{}:
+			for _, v := range val {
+				h.Add(name, fmt.Sprint(v))
+			}
+		case []string:
+			for _, v := range val {
+				h.Add(name, v)
+			}
+		case nil:
+		default:
+			h.Add(name, fmt.Sprint(val))
+		}
+	}
+	return h
+}
+
+// HeadersToJS converts h into a JavaScript configuration object. Keys are
+// canonicalized and multiple values are joined with ", " as permitted by
+// RFC 7230.
+func HeadersToJS(h http.Header) js.M {
+	m := js.M{}
+	for name, values := range h {
+		if len(values) == 0 {
+			continue
+		}
+		m[http.CanonicalHeaderKey(name)] = strings.Join(values, ", ")
+	}
+	return m
+}
+
+// SetRequestHeaders sets all values of h on the request using a single
+// JavaScript call where possible. Like SetRequestHeader, it adds to
+// headers that are already set, and the browser combines multiple values
+// of a header into a comma-separated list.
+//
+// A map[string][]string can be passed directly.
+func (r *Request) SetRequestHeaders(h http.Header) {
+	for name, values := range h {
+		for _, value := range values {
+			r.header.Add(name, value)
+		}
+	}
+	if r.opened {
+		r.record(r.writeHeaders(h))
+	}
+}
+
+var (
+	applyHeadersOnce sync.Once
+	applyHeadersFunc *js.Object
+)
+
+// applyHeaders returns a function that sets headers given as a flat
+// [name, value, ...] array on an XMLHttpRequest. Setting many headers
+// with a single call is considerably faster under GopherJS than crossing
+// the JS boundary once per header.
+//
+// The function is created on first use rather than at init, so that
+// merely importing the package doesn't require a Content Security
+// Policy allowing 'unsafe-eval'. It is nil if the policy forbids it.
+func applyHeaders() *js.Object {
+	applyHeadersOnce.Do(func() {
+		catch(func() {
+			applyHeadersFunc = js.Global.Get("Function").New("x", "h", "for (var i = 0; i < h.length; i += 2) x.setRequestHeader(h[i], h[i + 1]);")
+		})
+	})
+	return applyHeadersFunc
+}
+
+// writeHeaders sets all values of h on the underlying XMLHttpRequest
+// without recording them, using a single JavaScript call if possible.
+func (r *Request) writeHeaders(h http.Header) error {
+	n := 0
+	for _, values := range h {
+		n += len(values)
+	}
+	if n == 0 {
+		return nil
+	}
+
+	apply := applyHeaders()
+	if apply == nil {
+		for name, values := range h {
+			for _, value := range values {
+				if err := r.call("setRequestHeader", name, value); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	flat := make([]string, 0, 2*n)
+	for name, values := range h {
+		for _, value := range values {
+			flat = append(flat, name, value)
+		}
+	}
+	if !r.StrictErrors {
+		apply.Invoke(r.Object, flat)
+		return nil
+	}
+	return catch(func() {
+		apply.Invoke(r.Object, flat)
+	})
+}
+
+// LookupHeaders returns the values of the named response headers, in the
+// same order as names. Missing headers have an empty value. Header names
+// are case-insensitive and repeated headers are joined with ", ".
+//
+// Unlike ResponseHeader, the header blob is retrieved and scanned only
+// once, without building intermediate slices or maps, which makes it
+// the cheapest way to look up a few known headers.
+func (r *Request) LookupHeaders(names ...string) []string {
+	values := make([]string, len(names))
+	raw := r.ResponseHeaders()
+
+	for len(raw) > 0 {
+		line := raw
+		if i := strings.IndexByte(raw, '\n'); i >= 0 {
+			line, raw = raw[:i], raw[i+1:]
+		} else {
+			raw = ""
+		}
+
+		colon := strings.IndexByte(line, ':')
+		if colon < 0 {
+			continue
+		}
+		name := line[:colon]
+		for i, want := range names {
+			if !strings.EqualFold(name, want) {
+				continue
+			}
+			value := strings.TrimSpace(line[colon+1:])
+			if values[i] == "" {
+				values[i] = value
+			} else {
+				values[i] += ", " + value
+			}
+		}
+	}
+	return values
+}
+
+// ContentLength returns the value of the response's "Content-Length"
+// header, or -1 if it is missing or invalid. It is available as soon as
+// ReadyState is HeadersReceived, so it can be used to size buffers or
+// progress bars before the body arrives.
+func (r *Request) ContentLength() int64 {
+	if r.ReadyState < HeadersReceived {
+		return -1
+	}
+	n, err := strconv.ParseInt(r.ResponseHeader("Content-Length"), 10, 64)
+	if err != nil || n < 0 {
+		return -1
+	}
+	return n
+}
+
+// ResponseHeaderMap returns all response headers as an http.Header, for
+// use with code that expects the standard library's representation. Keys
+// are canonicalized and repeated headers have multiple values.
+func (r *Request) ResponseHeaderMap() http.Header {
+	return parseHeaders(r.ResponseHeaders())
+}
+
+// parseHeaders parses the output of getAllResponseHeaders.
+func parseHeaders(raw string) http.Header {
+	h := http.Header{}
+	for _, line := range strings.Split(raw, "\n") {
+		colon := strings.IndexByte(line, ':')
+		if colon < 0 {
+			continue
+		}
+		h.Add(strings.TrimSpace(line[:colon]), strings.TrimSpace(line[colon+1:]))
+	}
+	return h
+}
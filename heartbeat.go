@@ -0,0 +1,153 @@
+package xhr
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+	"honnef.co/go/js/util"
+)
+
+// Heartbeat pings an endpoint periodically to track whether the server
+// is reachable, e.g. to drive a "connected/disconnected" indicator.
+//
+// Failed pings are retried with exponential backoff. Pinging pauses while
+// the tab is hidden and resumes immediately when it becomes visible.
+type Heartbeat struct {
+	// URL is pinged with GET requests. A 2xx status means alive.
+	URL string
+
+	// Client sends the requests. A zero Client is used when nil.
+	Client *Client
+
+	// Interval between successful pings. It defaults to 30s.
+	Interval time.Duration
+
+	// Jitter randomizes every delay by up to the given fraction, e.g.
+	// 0.1 for ±10%, to avoid synchronized pings from many clients.
+	Jitter float64
+
+	// MaxBackoff caps the delay after failed pings. It defaults to 5m.
+	MaxBackoff time.Duration
+
+	// Timeout of each ping. It defaults to 10s.
+	Timeout time.Duration
+
+	// OnChange, if set, is called whenever the liveness state changes.
+	OnChange func(alive bool)
+
+	mu    sync.Mutex
+	alive bool
+	known bool // alive has been determined at least once
+}
+
+// Alive reports the result of the most recent ping.
+func (h *Heartbeat) Alive() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.alive
+}
+
+// Run pings until ctx is done. It is usually started in its own
+// goroutine.
+func (h *Heartbeat) Run(ctx context.Context) {
+	visible := make(chan struct{}, 1)
+	doc := js.Global.Get("document")
+	if doc != js.Undefined {
+		t := util.EventTarget{Object: doc}
+		onChange := func(*js.Object) {
+			select {
+			case visible <- struct{}{}:
+			default:
+			}
+		}
+		t.AddEventListener("visibilitychange", false, onChange)
+		defer t.RemoveEventListener("visibilitychange", false, onChange)
+	}
+	hidden := func() bool {
+		return doc != js.Undefined && doc.Get("visibilityState").String() == "hidden"
+	}
+
+	failures := 0
+	for {
+		for hidden() {
+			select {
+			case <-ctx.Done():
+				return
+			case <-visible:
+			}
+		}
+
+		ok := h.ping(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		h.set(ok)
+		if ok {
+			failures = 0
+		} else {
+			failures++
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(h.delay(failures)):
+		}
+	}
+}
+
+func (h *Heartbeat) ping(ctx context.Context) bool {
+	c := h.Client
+	if c == nil {
+		c = &Client{}
+	}
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req := c.NewRequest("GET", h.URL)
+	err := c.Do(ctx, req, nil)
+	return err == nil && req.IsStatus2xx()
+}
+
+func (h *Heartbeat) set(alive bool) {
+	h.mu.Lock()
+	changed := !h.known || h.alive != alive
+	h.alive, h.known = alive, true
+	h.mu.Unlock()
+
+	if changed && h.OnChange != nil {
+		h.OnChange(alive)
+	}
+}
+
+// delay returns the time until the next ping after the given number of
+// consecutive failures.
+func (h *Heartbeat) delay(failures int) time.Duration {
+	d := h.Interval
+	if d == 0 {
+		d = 30 * time.Second
+	}
+	limit := h.MaxBackoff
+	if limit == 0 {
+		limit = 5 * time.Minute
+	}
+
+	for i := 1; i < failures && d < limit; i++ {
+		d *= 2
+	}
+	if d > limit {
+		d = limit
+	}
+	if h.Jitter > 0 {
+		d += time.Duration((rand.Float64()*2 - 1) * h.Jitter * float64(d))
+	}
+	return d
+}
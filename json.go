@@ -0,0 +1,256 @@
+package xhr
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gopherjs/gopherjs/js"
+	"github.com/rocketlaunchr/react/forks/encoding/json"
+)
+
+// JSONStrategy selects how JSON is decoded.
+type JSONStrategy int
+
+const (
+	// JSONAuto uses native parsing for payloads of at least
+	// NativeJSONThreshold bytes that are decoded into generic Go values,
+	// and encoding/json otherwise.
+	JSONAuto JSONStrategy = iota
+	// JSONNative uses JSON.parse whenever the target type permits it.
+	JSONNative
+	// JSONGo always uses encoding/json.
+	JSONGo
+)
+
+// NativeJSONThreshold is the payload size in bytes from which JSONAuto
+// switches to native parsing. Native parsing is dramatically faster for
+// large payloads under GopherJS, but the conversion into Go values has a
+// fixed overhead that dominates for small ones.
+var NativeJSONThreshold = 16 * 1024
+
+// DecodeJSON decodes the JSON-encoded text into v according to strategy.
+//
+// Native parsing is only possible when v is a *interface{},
+// *map[string]interface{} or *[]interface{}. For all other types,
+// encoding/json is used regardless of strategy.
+func DecodeJSON(text string, v interface{}, strategy JSONStrategy) error {
+	if strategy == JSONGo || !nativeJSONTarget(v) {
+		return json.Unmarshal([]byte(text), v)
+	}
+	if strategy == JSONAuto && len(text) < NativeJSONThreshold {
+		return json.Unmarshal([]byte(text), v)
+	}
+
+	o, err := parseJSON(text)
+	if err != nil {
+		return err
+	}
+	return assignNative(o.Interface(), v)
+}
+
+// parseJSON calls JSON.parse, converting exceptions into errors.
+func parseJSON(text string) (o *js.Object, err error) {
+	err = catch(func() {
+		o = js.Global.Get("JSON").Call("parse", text)
+	})
+	return o, err
+}
+
+func nativeJSONTarget(v interface{}) bool {
+	switch v.(type) {
+	case *interface{}, *map[string]interface{}, *[]interface{}:
+		return true
+	}
+	return false
+}
+
+// assignNative stores val, as returned by (*js.Object).Interface, into
+// the native JSON target v.
+func assignNative(val interface{}, v interface{}) error {
+	switch v := v.(type) {
+	case *interface{}:
+		*v = val
+		return nil
+	case *map[string]interface{}:
+		if val == nil {
+			*v = nil
+			return nil
+		}
+		if m, ok := val.(map[string]interface{}); ok {
+			*v = m
+			return nil
+		}
+	case *[]interface{}:
+		if val == nil {
+			*v = nil
+			return nil
+		}
+		if s, ok := val.([]interface{}); ok {
+			*v = s
+			return nil
+		}
+	}
+	return fmt.Errorf("json: cannot unmarshal %T into %T", val, v)
+}
+
+// ErrJSONPathNotFound is returned by JSONGet when nothing exists at the
+// requested path.
+var ErrJSONPathNotFound = errors.New("json path not found")
+
+// jsonLookup walks the path of keys starting at o. It returns undefined
+// when the path does not exist, which can't be confused with a JSON
+// value.
+func jsonLookup(o *js.Object, keys []string) *js.Object {
+	hasOwn := js.Global.Get("Object").Get("prototype").Get("hasOwnProperty")
+	for _, key := range keys {
+		// Object(o) returns o itself only for objects and arrays.
+		if o == nil || o == js.Undefined || js.Global.Call("Object", o) != o {
+			return js.Undefined
+		}
+		if !hasOwn.Call("call", o, key).Bool() {
+			return js.Undefined
+		}
+		o = o.Get(key)
+	}
+	return o
+}
+
+// JSONGet returns the value found at path within the JSON response,
+// without converting the entire payload into Go values. This suits
+// dashboards that only need a handful of fields from huge responses.
+//
+// path is a dot-separated list of object keys and array indices, such as
+// "data.items.0.id". An empty path returns the whole document. The value
+// is returned as produced by (*js.Object).Interface, so numbers are
+// float64, objects are map[string]interface{} and arrays are
+// []interface{}.
+//
+// When ResponseType is JSON, the already parsed response is used.
+// Otherwise ResponseText is parsed once and reused by later calls.
+func (r *Request) JSONGet(path string) (interface{}, error) {
+	root, err := r.jsonRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	if path != "" {
+		keys = strings.Split(path, ".")
+	}
+	o := jsonLookup(root, keys)
+	if o == js.Undefined {
+		return nil, ErrJSONPathNotFound
+	}
+	if o == nil {
+		return nil, nil
+	}
+	return o.Interface(), nil
+}
+
+// jsonRoot returns the natively parsed JSON response.
+func (r *Request) jsonRoot() (*js.Object, error) {
+	if r.ResponseType == JSON {
+		return r.Response, r.checkJSONResponse()
+	}
+	if r.parsedJSON == nil {
+		o, err := parseJSON(r.ResponseText)
+		if err != nil {
+			return nil, err
+		}
+		r.parsedJSON = o
+	}
+	return r.parsedJSON, nil
+}
+
+// ErrInvalidJSONResponse is returned when the response of a request with
+// ResponseType JSON is not valid JSON.
+var ErrInvalidJSONResponse = errors.New("invalid JSON response")
+
+// checkJSONResponse returns ErrInvalidJSONResponse if the browser failed
+// to parse the body of a request with ResponseType JSON, in which case
+// Response is null. Since the text of such responses isn't accessible, a
+// body consisting of the JSON literal null is indistinguishable from
+// invalid JSON.
+func (r *Request) checkJSONResponse() error {
+	if r.Response != nil || r.Status == http.StatusNoContent || r.method == "HEAD" || r.ContentLength() == 0 {
+		return nil
+	}
+	return ErrInvalidJSONResponse
+}
+
+// JSON decodes the JSON response into v.
+//
+// When ResponseType is JSON, the response already parsed by the browser
+// is used. It is assigned directly to generic Go values, and re-encoded
+// for encoding/json otherwise. ErrInvalidJSONResponse is returned if the
+// browser failed to parse the response, which includes a response of
+// null, so APIs that may respond with null should be used with Text. For
+// other response types, the response text is decoded with DecodeJSON
+// using JSONAuto.
+func (r *Request) JSON(v interface{}) error {
+	switch r.ResponseType {
+	case JSON:
+		if err := r.checkJSONResponse(); err != nil {
+			return err
+		}
+		if nativeJSONTarget(v) {
+			var val interface{}
+			if r.Response != nil {
+				val = r.Response.Interface()
+			}
+			return assignNative(val, v)
+		}
+		text := js.Global.Get("JSON").Call("stringify", r.Response).String()
+		return json.Unmarshal([]byte(text), v)
+	case ArrayBuffer:
+		return DecodeJSON(string(r.ResponseBytes()), v, JSONAuto)
+	}
+
+	if r.parsedJSON != nil && nativeJSONTarget(v) {
+		return assignNative(r.parsedJSON.Interface(), v)
+	}
+	return DecodeJSON(r.ResponseText, v, JSONAuto)
+}
+
+// DecodeJSONStrict decodes the JSON-encoded text into v like
+// encoding/json, but fails on fields that don't exist in v and on
+// trailing data. Numbers decoded into interface{} values are
+// json.Number rather than float64, so no precision is lost. This catches
+// drift between an API and its client instead of silently dropping
+// fields.
+func DecodeJSONStrict(text string, v interface{}) error {
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.DisallowUnknownFields()
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	var extra interface{}
+	if err := dec.Decode(&extra); err != io.EOF {
+		return errors.New("json: unexpected data after top-level value")
+	}
+	return nil
+}
+
+// DecodeStrict decodes the JSON response into v like JSON, but with the
+// strict semantics of DecodeJSONStrict.
+//
+// When ResponseType is JSON, the browser has already parsed numbers into
+// float64 values, so large integers may have lost precision. Use Text or
+// ArrayBuffer to preserve them.
+func (r *Request) DecodeStrict(v interface{}) error {
+	switch r.ResponseType {
+	case JSON:
+		if err := r.checkJSONResponse(); err != nil {
+			return err
+		}
+		text := js.Global.Get("JSON").Call("stringify", r.Response).String()
+		return DecodeJSONStrict(text, v)
+	case ArrayBuffer:
+		return DecodeJSONStrict(string(r.ResponseBytes()), v)
+	}
+	return DecodeJSONStrict(r.ResponseText, v)
+}
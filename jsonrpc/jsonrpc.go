@@ -0,0 +1,255 @@
+// Package jsonrpc provides a JSON-RPC 2.0 client built on package xhr.
+//
+// Calls and notifications made within the same tick are automatically
+// combined into a single batch request.
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rocketlaunchr/react/forks/encoding/json"
+
+	xhr "github.com/rocketlaunchr/gopherjs-xhr"
+)
+
+// ErrNoResponse is returned by Call when the server's reply did not
+// contain a response for the call.
+var ErrNoResponse = errors.New("jsonrpc: no response for call")
+
+// Error is a JSON-RPC error object returned by the server.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc: %s (%d)", e.Message, e.Code)
+}
+
+// Client makes JSON-RPC 2.0 calls.
+type Client struct {
+	// Client prepares and sends the underlying requests, applying its
+	// headers and middleware.
+	*xhr.Client
+
+	// Endpoint is the url of the JSON-RPC endpoint. It is resolved
+	// against the Client's BaseURL.
+	Endpoint string
+
+	// BatchWindow is how long calls are collected before being sent. The
+	// default of zero combines calls made within the same tick.
+	BatchWindow time.Duration
+
+	mu      sync.Mutex
+	nextID  uint64
+	pending []*call
+}
+
+// NewClient returns a Client for the JSON-RPC endpoint at url.
+func NewClient(url string) *Client {
+	return &Client{Client: &xhr.Client{}, Endpoint: url}
+}
+
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      *uint64     `json:"id,omitempty"`
+}
+
+type response struct {
+	ID     *uint64         `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+type call struct {
+	ctx  context.Context
+	req  request
+	done chan *response // Receives nil when the batch failed
+	err  error          // Transport error, set before done is closed
+}
+
+// Call invokes method with params and decodes the result into result,
+// which may be nil. params must encode to a JSON array or object. Errors
+// returned by the server are of type *Error.
+//
+// The batch request is canceled once the contexts of all calls and
+// notifications in it are done.
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	c.mu.Unlock()
+
+	cl := c.enqueue(ctx, request{JSONRPC: "2.0", Method: method, Params: params, ID: &id})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case resp := <-cl.done:
+		if cl.err != nil {
+			return cl.err
+		}
+		if resp == nil {
+			return ErrNoResponse
+		}
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	}
+}
+
+// Notify invokes method without expecting a result. Only errors sending
+// the batch are returned.
+func (c *Client) Notify(ctx context.Context, method string, params interface{}) error {
+	cl := c.enqueue(ctx, request{JSONRPC: "2.0", Method: method, Params: params})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-cl.done:
+		return cl.err
+	}
+}
+
+func (c *Client) enqueue(ctx context.Context, req request) *call {
+	cl := &call{ctx: ctx, req: req, done: make(chan *response, 1)}
+
+	c.mu.Lock()
+	c.pending = append(c.pending, cl)
+	if len(c.pending) == 1 {
+		time.AfterFunc(c.BatchWindow, c.flush)
+	}
+	c.mu.Unlock()
+
+	return cl
+}
+
+// flush sends all pending calls as a single request. Calls whose
+// params can't be encoded fail on their own without failing the batch.
+func (c *Client) flush() {
+	c.mu.Lock()
+	calls := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	var (
+		sent []*call
+		raws [][]byte
+	)
+	for _, cl := range calls {
+		b, err := json.Marshal(cl.req)
+		if err != nil {
+			cl.err = err
+			cl.done <- nil
+			continue
+		}
+		sent = append(sent, cl)
+		raws = append(raws, b)
+	}
+	if len(sent) == 0 {
+		return
+	}
+
+	ctx, cancel := batchContext(sent)
+	defer cancel()
+	resps, err := c.send(ctx, raws)
+
+	byID := map[uint64]*response{}
+	for _, resp := range resps {
+		if resp.ID != nil {
+			byID[*resp.ID] = resp
+		}
+	}
+	if len(sent) == 1 && len(resps) == 1 && sent[0].req.ID != nil {
+		// Errors such as parse errors are returned with a null id.
+		byID[*sent[0].req.ID] = resps[0]
+	}
+
+	for _, cl := range sent {
+		cl.err = err
+		if cl.req.ID != nil {
+			cl.done <- byID[*cl.req.ID]
+		} else {
+			cl.done <- nil
+		}
+	}
+}
+
+// batchContext returns a context that is done once the contexts of all
+// calls are done, so that a batch is only abandoned when no caller waits
+// for it anymore.
+func batchContext(calls []*call) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	wg.Add(len(calls))
+	for _, cl := range calls {
+		go func(done <-chan struct{}) {
+			defer wg.Done()
+			select {
+			case <-done:
+			case <-ctx.Done():
+			}
+		}(cl.ctx.Done())
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+	}()
+	return ctx, cancel
+}
+
+// send posts the encoded requests, as a batch if there are several.
+func (c *Client) send(ctx context.Context, raws [][]byte) ([]*response, error) {
+	b := raws[0]
+	if len(raws) > 1 {
+		b = append([]byte{'['}, bytes.Join(raws, []byte{','})...)
+		b = append(b, ']')
+	}
+
+	req := c.NewRequest("POST", c.Endpoint)
+	req.ResponseType = xhr.Text
+	req.SetRequestHeader("Content-Type", xhr.ApplicationJSON)
+	req.SetRequestHeader("Accept", xhr.ApplicationJSON)
+
+	err := c.Do(ctx, req, string(b))
+	if err != nil {
+		return nil, err
+	}
+
+	raw := req.ResponseBytes()
+	if len(raw) == 0 {
+		if !req.IsStatus2xx() {
+			return nil, xhr.NewStatusError(req)
+		}
+		return nil, nil // Notifications only
+	}
+
+	var resps []*response
+	if raw[0] == '[' {
+		err = json.Unmarshal(raw, &resps)
+	} else {
+		var resp response
+		err = json.Unmarshal(raw, &resp)
+		resps = []*response{&resp}
+	}
+	if err != nil {
+		if !req.IsStatus2xx() {
+			return nil, xhr.NewStatusError(req)
+		}
+		return nil, err
+	}
+	return resps, nil
+}
@@ -0,0 +1,475 @@
+// Package jsonschema validates JSON responses of package xhr against a
+// JSON Schema.
+//
+// A commonly used subset of JSON Schema is supported: type, enum, const,
+// properties, required, additionalProperties, items, minItems,
+// maxItems, uniqueItems, minLength, maxLength, pattern, minimum,
+// maximum, exclusiveMinimum, exclusiveMaximum, multipleOf, allOf,
+// anyOf, oneOf, not, and local $ref pointers such as
+// "#/definitions/user" or "#/$defs/user". Other keywords are ignored.
+//
+//	schema, err := jsonschema.Compile([]byte(`{"type": "object", "required": ["id"]}`))
+//	req.Validator = schema.ValidateResponse
+//	err = req.Decode(&user)
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rocketlaunchr/react/forks/encoding/json"
+
+	xhr "github.com/rocketlaunchr/gopherjs-xhr"
+)
+
+// FieldError describes a single violation of the schema.
+type FieldError struct {
+	// Path is the dot-separated path of the offending value, such as
+	// "items.0.id". It is empty for the document itself.
+	Path    string
+	Message string
+}
+
+func (e FieldError) String() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError is returned when a document doesn't conform to a
+// schema. It lists all violations found.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.String()
+	}
+	return "jsonschema: " + strings.Join(msgs, "; ")
+}
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	root *node
+}
+
+// node is a compiled schema or subschema.
+type node struct {
+	boolean *bool // Set for the boolean schemas true and false
+	ref     string
+
+	types    []string
+	enum     []interface{}
+	constant *interface{}
+
+	properties           map[string]*node
+	required             []string
+	additionalProperties *node
+	items                *node
+	minItems, maxItems   *int
+	uniqueItems          bool
+
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+
+	minimum, maximum                   *float64
+	exclusiveMinimum, exclusiveMaximum *float64
+	multipleOf                         *float64
+
+	allOf, anyOf, oneOf []*node
+	not                 *node
+
+	defs map[string]*node // definitions and $defs of the root
+}
+
+// Compile parses a JSON Schema.
+func Compile(schema []byte) (*Schema, error) {
+	var raw interface{}
+	if err := json.Unmarshal(schema, &raw); err != nil {
+		return nil, err
+	}
+	root, err := compile(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+// MustCompile is like Compile but panics if the schema can't be parsed.
+// It simplifies the initialization of global variables.
+func MustCompile(schema string) *Schema {
+	s, err := Compile([]byte(schema))
+	if err != nil {
+		panic("jsonschema: Compile(" + strconv.Quote(schema) + "): " + err.Error())
+	}
+	return s
+}
+
+// Validate checks v, a document decoded into generic Go values such as
+// map[string]interface{}, []interface{} and float64, against the schema.
+// It returns a *ValidationError if v doesn't conform.
+func (s *Schema) Validate(v interface{}) error {
+	var errs []FieldError
+	s.validate(s.root, v, "", &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// ValidateResponse checks the JSON response of r against the schema. It
+// can be used as a Request's Validator, so that Decode fails with a
+// *ValidationError for non-conforming responses.
+func (s *Schema) ValidateResponse(r *xhr.Request) error {
+	var v interface{}
+	if err := r.JSON(&v); err != nil {
+		return err
+	}
+	return s.Validate(v)
+}
+
+func compile(raw interface{}) (*node, error) {
+	switch raw := raw.(type) {
+	case bool:
+		return &node{boolean: &raw}, nil
+	case map[string]interface{}:
+		n, err := compileObject(raw)
+		if err != nil {
+			return nil, err
+		}
+		n.defs = map[string]*node{}
+		for _, key := range []string{"definitions", "$defs"} {
+			defs, _ := raw[key].(map[string]interface{})
+			for name, def := range defs {
+				d, err := compileSub(def)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %v", key, name, err)
+				}
+				n.defs["#/"+key+"/"+name] = d
+			}
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("schema must be an object or a boolean, not %T", raw)
+}
+
+func compileObject(m map[string]interface{}) (*node, error) {
+	n := &node{}
+	var err error
+
+	if ref, ok := m["$ref"].(string); ok {
+		n.ref = ref
+	}
+
+	switch t := m["type"].(type) {
+	case string:
+		n.types = []string{t}
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				n.types = append(n.types, s)
+			}
+		}
+	}
+
+	if enum, ok := m["enum"].([]interface{}); ok {
+		n.enum = enum
+	}
+	if c, ok := m["const"]; ok {
+		n.constant = &c
+	}
+
+	if props, ok := m["properties"].(map[string]interface{}); ok {
+		n.properties = map[string]*node{}
+		for name, p := range props {
+			if n.properties[name], err = compileSub(p); err != nil {
+				return nil, fmt.Errorf("properties.%s: %v", name, err)
+			}
+		}
+	}
+	if req, ok := m["required"].([]interface{}); ok {
+		for _, v := range req {
+			if s, ok := v.(string); ok {
+				n.required = append(n.required, s)
+			}
+		}
+	}
+	if ap, ok := m["additionalProperties"]; ok {
+		if n.additionalProperties, err = compileSub(ap); err != nil {
+			return nil, fmt.Errorf("additionalProperties: %v", err)
+		}
+	}
+	if items, ok := m["items"]; ok {
+		if n.items, err = compileSub(items); err != nil {
+			return nil, fmt.Errorf("items: %v", err)
+		}
+	}
+	n.minItems = intKeyword(m, "minItems")
+	n.maxItems = intKeyword(m, "maxItems")
+	n.uniqueItems, _ = m["uniqueItems"].(bool)
+
+	n.minLength = intKeyword(m, "minLength")
+	n.maxLength = intKeyword(m, "maxLength")
+	if p, ok := m["pattern"].(string); ok {
+		if n.pattern, err = regexp.Compile(p); err != nil {
+			return nil, fmt.Errorf("pattern: %v", err)
+		}
+	}
+
+	n.minimum = numKeyword(m, "minimum")
+	n.maximum = numKeyword(m, "maximum")
+	n.exclusiveMinimum = numKeyword(m, "exclusiveMinimum")
+	n.exclusiveMaximum = numKeyword(m, "exclusiveMaximum")
+	n.multipleOf = numKeyword(m, "multipleOf")
+
+	for key, dst := range map[string]*[]*node{"allOf": &n.allOf, "anyOf": &n.anyOf, "oneOf": &n.oneOf} {
+		list, _ := m[key].([]interface{})
+		for i, sub := range list {
+			c, err := compileSub(sub)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%d: %v", key, i, err)
+			}
+			*dst = append(*dst, c)
+		}
+	}
+	if not, ok := m["not"]; ok {
+		if n.not, err = compileSub(not); err != nil {
+			return nil, fmt.Errorf("not: %v", err)
+		}
+	}
+	return n, nil
+}
+
+// compileSub compiles a subschema, which can't declare definitions of
+// its own.
+func compileSub(raw interface{}) (*node, error) {
+	switch raw := raw.(type) {
+	case bool:
+		return &node{boolean: &raw}, nil
+	case map[string]interface{}:
+		return compileObject(raw)
+	}
+	return nil, fmt.Errorf("schema must be an object or a boolean, not %T", raw)
+}
+
+func numKeyword(m map[string]interface{}, key string) *float64 {
+	if f, ok := m[key].(float64); ok {
+		return &f
+	}
+	return nil
+}
+
+func intKeyword(m map[string]interface{}, key string) *int {
+	if f, ok := m[key].(float64); ok {
+		i := int(f)
+		return &i
+	}
+	return nil
+}
+
+func (s *Schema) validate(n *node, v interface{}, path string, errs *[]FieldError) {
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if n.boolean != nil {
+		if !*n.boolean {
+			fail("not allowed")
+		}
+		return
+	}
+	if n.ref != "" {
+		ref := s.root.defs[n.ref]
+		if n.ref == "#" {
+			ref = s.root
+		}
+		if ref == nil {
+			fail("unresolvable $ref %q", n.ref)
+			return
+		}
+		s.validate(ref, v, path, errs)
+	}
+
+	if len(n.types) > 0 {
+		ok := false
+		for _, t := range n.types {
+			if hasType(v, t) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			fail("expected %s, got %s", strings.Join(n.types, " or "), typeOf(v))
+			return
+		}
+	}
+
+	if n.enum != nil {
+		ok := false
+		for _, e := range n.enum {
+			if reflect.DeepEqual(v, e) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			fail("must be one of %v", n.enum)
+		}
+	}
+	if n.constant != nil && !reflect.DeepEqual(v, *n.constant) {
+		fail("must be %v", *n.constant)
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, name := range n.required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, FieldError{Path: join(path, name), Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names) // Report errors in a deterministic order
+		for _, name := range names {
+			val := v[name]
+			if p, ok := n.properties[name]; ok {
+				s.validate(p, val, join(path, name), errs)
+			} else if n.additionalProperties != nil {
+				if ap := n.additionalProperties; ap.boolean != nil && !*ap.boolean {
+					*errs = append(*errs, FieldError{Path: join(path, name), Message: "is not allowed"})
+				} else {
+					s.validate(ap, val, join(path, name), errs)
+				}
+			}
+		}
+
+	case []interface{}:
+		if n.minItems != nil && len(v) < *n.minItems {
+			fail("must have at least %d items", *n.minItems)
+		}
+		if n.maxItems != nil && len(v) > *n.maxItems {
+			fail("must have at most %d items", *n.maxItems)
+		}
+		if n.uniqueItems {
+		unique:
+			for i := range v {
+				for j := 0; j < i; j++ {
+					if reflect.DeepEqual(v[i], v[j]) {
+						fail("items %d and %d must be unique", j, i)
+						break unique
+					}
+				}
+			}
+		}
+		if n.items != nil {
+			for i, item := range v {
+				s.validate(n.items, item, join(path, strconv.Itoa(i)), errs)
+			}
+		}
+
+	case string:
+		l := utf8.RuneCountInString(v)
+		if n.minLength != nil && l < *n.minLength {
+			fail("must be at least %d characters long", *n.minLength)
+		}
+		if n.maxLength != nil && l > *n.maxLength {
+			fail("must be at most %d characters long", *n.maxLength)
+		}
+		if n.pattern != nil && !n.pattern.MatchString(v) {
+			fail("must match %q", n.pattern.String())
+		}
+
+	case float64:
+		if n.minimum != nil && v < *n.minimum {
+			fail("must be >= %v", *n.minimum)
+		}
+		if n.maximum != nil && v > *n.maximum {
+			fail("must be <= %v", *n.maximum)
+		}
+		if n.exclusiveMinimum != nil && v <= *n.exclusiveMinimum {
+			fail("must be > %v", *n.exclusiveMinimum)
+		}
+		if n.exclusiveMaximum != nil && v >= *n.exclusiveMaximum {
+			fail("must be < %v", *n.exclusiveMaximum)
+		}
+		if n.multipleOf != nil && *n.multipleOf != 0 {
+			if q := v / *n.multipleOf; q != math.Trunc(q) {
+				fail("must be a multiple of %v", *n.multipleOf)
+			}
+		}
+	}
+
+	for _, sub := range n.allOf {
+		s.validate(sub, v, path, errs)
+	}
+	if len(n.anyOf) > 0 && s.matches(n.anyOf, v) == 0 {
+		fail("must match at least one schema in anyOf")
+	}
+	if len(n.oneOf) > 0 {
+		if m := s.matches(n.oneOf, v); m != 1 {
+			fail("must match exactly one schema in oneOf, matched %d", m)
+		}
+	}
+	if n.not != nil && s.matches([]*node{n.not}, v) == 1 {
+		fail("must not match the schema in not")
+	}
+}
+
+// matches returns the number of schemas in list that v conforms to.
+func (s *Schema) matches(list []*node, v interface{}) int {
+	count := 0
+	for _, n := range list {
+		var errs []FieldError
+		s.validate(n, v, "", &errs)
+		if len(errs) == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func hasType(v interface{}, t string) bool {
+	switch t {
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	default:
+		return typeOf(v) == t
+	}
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
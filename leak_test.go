@@ -0,0 +1,87 @@
+//go:build js
+
+package xhr
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSendRemovesListeners(t *testing.T) {
+	url := testServer(t)
+
+	req := NewRequest("GET", url+"/bytes?n=16")
+	if err := req.Send(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := req.ActiveListeners(); n != 0 {
+		t.Errorf("%d listeners left", n)
+	}
+}
+
+func TestCanceledSendRemovesListeners(t *testing.T) {
+	url := testServer(t)
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req := NewRequest("GET", url+"/slow?ms=200")
+	if err := req.Send(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if n := req.ActiveListeners(); n != 0 {
+		t.Errorf("%d listeners left", n)
+	}
+	checkGoroutines(t, before)
+}
+
+func TestAbortRemovesListeners(t *testing.T) {
+	url := testServer(t)
+	before := runtime.NumGoroutine()
+
+	req := NewRequest("GET", url+"/slow?ms=200")
+	req.OnLoad(func(ProgressEvent) {})
+	time.AfterFunc(10*time.Millisecond, req.Abort)
+	if err := req.Send(context.Background(), nil); !errors.Is(err, ErrAborted) {
+		t.Fatalf("got %v, want %v", err, ErrAborted)
+	}
+	if n := req.ActiveListeners(); n != 0 {
+		t.Errorf("%d listeners left", n)
+	}
+	checkGoroutines(t, before)
+}
+
+func TestTimeoutRemovesListeners(t *testing.T) {
+	url := testServer(t)
+	before := runtime.NumGoroutine()
+
+	req := NewRequest("GET", url+"/slow?ms=200")
+	req.Timeout = 10 * time.Millisecond
+	req.HeadersTimeout = 5 * time.Millisecond
+	if err := req.Send(context.Background(), nil); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("got %v, want %v", err, ErrTimeout)
+	}
+	if n := req.ActiveListeners(); n != 0 {
+		t.Errorf("%d listeners left", n)
+	}
+	checkGoroutines(t, before)
+}
+
+func TestCompletedBeforeCancelRemovesListeners(t *testing.T) {
+	url := testServer(t)
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := NewRequest("GET", url+"/bytes?n=16")
+	if err := req.Send(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	cancel() // Must not affect the completed request
+	if n := req.ActiveListeners(); n != 0 {
+		t.Errorf("%d listeners left", n)
+	}
+	checkGoroutines(t, before)
+}
@@ -0,0 +1,62 @@
+package xhr
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rocketlaunchr/react/forks/encoding/json"
+)
+
+// ApplicationNDJSON is the "Content-Type" of newline-delimited JSON.
+const ApplicationNDJSON = "application/x-ndjson"
+
+// StreamNDJSON sends a request through c and decodes the newline-delimited
+// JSON response incrementally, sending each value on out as soon as its
+// line has arrived rather than once the request is done. This allows
+// log-tail and export endpoints to be processed while they are still
+// streaming. Empty lines are skipped.
+//
+// StreamNDJSON returns once the response is complete, and doesn't close
+// out. If a line can't be decoded into T, the request is aborted and the
+// error is returned.
+func StreamNDJSON[T any](ctx context.Context, c *Client, method, url string, data interface{}, out chan<- T) error {
+	req := c.NewRequest(method, url)
+	req.SetRequestHeader("Accept", ApplicationNDJSON)
+
+	var buf string
+	emit := func(line string) error {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return nil
+		}
+		var v T
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			return err
+		}
+		select {
+		case out <- v:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	err := c.DoStreaming(ctx, req, data, func(chunk string) error {
+		buf += chunk
+		for {
+			i := strings.IndexByte(buf, '\n')
+			if i < 0 {
+				return nil
+			}
+			line := buf[:i]
+			buf = buf[i+1:]
+			if err := emit(line); err != nil {
+				return err
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return emit(buf) // The last line may lack a trailing newline
+}
@@ -0,0 +1,276 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/gopherjs/gopherjs/js"
+	"github.com/rocketlaunchr/react/forks/encoding/json"
+)
+
+// ErrPageLimit is delivered by Paginate when PaginateOptions.MaxPages
+// pages have been fetched but more remain.
+var ErrPageLimit = errors.New("page limit reached")
+
+// PageStrategy determines the url of the page following the page fetched
+// by req. It returns an empty string when there are no more pages.
+// Relative urls are resolved against the url of the response, not the
+// BaseURL of the Client.
+type PageStrategy func(req *Request) (next string, err error)
+
+// Links parses the RFC 5988 Link header of the response into a map from
+// relation type, such as "next" or "last", to url. Relative urls are
+// resolved against the url of the response.
+func (r *Request) Links() map[string]string {
+	base := r.responseBase()
+	links := parseLinkHeader(r.ResponseHeader("Link"))
+	for rel, target := range links {
+		links[rel] = resolveURL(base, target)
+	}
+	return links
+}
+
+// responseBase returns the url that relative urls of the response are
+// resolved against: the final url of the response after redirects, or
+// else the url of the request.
+func (r *Request) responseBase() string {
+	if u := r.Get("responseURL"); u != nil && u != js.Undefined && u.String() != "" {
+		return u.String()
+	}
+	return r.URL()
+}
+
+// Next returns the url of the next page given by the Link header, or an
+// empty string if there is none.
+func (r *Request) Next() string {
+	return r.Links()["next"]
+}
+
+// Prev returns the url of the previous page given by the Link header, or
+// an empty string if there is none.
+func (r *Request) Prev() string {
+	links := r.Links()
+	if prev := links["prev"]; prev != "" {
+		return prev
+	}
+	return links["previous"]
+}
+
+// LinkPages is a PageStrategy that follows the rel="next" url of the
+// Link header.
+func LinkPages(req *Request) (string, error) {
+	return req.Next(), nil
+}
+
+// CursorPages returns a PageStrategy for cursor-based pagination. The
+// cursor is read from the JSON response at path (see JSONGet) and sent as
+// the query parameter param. Pagination ends when the cursor is missing,
+// null or empty.
+func CursorPages(path, param string) PageStrategy {
+	return func(req *Request) (string, error) {
+		cursor, err := req.JSONGet(path)
+		if err != nil && err != ErrJSONPathNotFound {
+			return "", err
+		}
+		if err == ErrJSONPathNotFound || cursor == nil || cursor == "" {
+			return "", nil
+		}
+		if f, ok := cursor.(float64); ok {
+			cursor = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return setQuery(req.responseBase(), param, fmt.Sprint(cursor))
+	}
+}
+
+// NumberedPages returns a PageStrategy that increments the query
+// parameter param, which starts at 1 if absent. Pagination ends when the
+// JSON array found at itemsPath (see JSONGet) is empty.
+func NumberedPages(param, itemsPath string) PageStrategy {
+	return func(req *Request) (string, error) {
+		items, err := req.JSONGet(itemsPath)
+		if err != nil && err != ErrJSONPathNotFound {
+			return "", err
+		}
+		if s, _ := items.([]interface{}); len(s) == 0 {
+			return "", nil
+		}
+
+		base := req.responseBase()
+		u, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		n := 1
+		if v := u.Query().Get(param); v != "" {
+			n, err = strconv.Atoi(v)
+			if err != nil {
+				return "", err
+			}
+		}
+		return setQuery(base, param, strconv.Itoa(n+1))
+	}
+}
+
+// PaginateOptions configures Paginate.
+type PaginateOptions struct {
+	// Strategy finds the next page. It defaults to LinkPages.
+	Strategy PageStrategy
+
+	// MaxPages is a safeguard against endless pagination. It defaults
+	// to 100.
+	MaxPages int
+}
+
+// PageResult is a single page delivered by Paginate.
+type PageResult struct {
+	// Request is the completed request of the page.
+	Request *Request
+	Err     error
+}
+
+// Paginate fetches successive pages starting at url and delivers them on
+// the returned channel, which is closed once the pages are exhausted, an
+// error is delivered or ctx is done. opts may be nil.
+//
+// Requests are sent through the client's middleware and a status code
+// other than 2xx is delivered as an error.
+func (c *Client) Paginate(ctx context.Context, method, url string, opts *PaginateOptions) <-chan PageResult {
+	strategy, maxPages := PageStrategy(LinkPages), 100
+	if opts != nil {
+		if opts.Strategy != nil {
+			strategy = opts.Strategy
+		}
+		if opts.MaxPages > 0 {
+			maxPages = opts.MaxPages
+		}
+	}
+
+	ch := make(chan PageResult)
+	go func() {
+		defer close(ch)
+
+		deliver := func(res PageResult) bool {
+			select {
+			case ch <- res:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		url = c.resolve(url)
+		for page := 0; url != ""; page++ {
+			if page == maxPages {
+				deliver(PageResult{Err: ErrPageLimit})
+				return
+			}
+
+			req := c.newRequest(method, url)
+			req.ResponseType = Text
+			err := c.Do(ctx, req, nil)
+			if err == nil && !req.IsStatus2xx() {
+				err = NewStatusError(req)
+			}
+			if err != nil {
+				deliver(PageResult{Request: req, Err: err})
+				return
+			}
+
+			if !deliver(PageResult{Request: req}) {
+				return
+			}
+
+			next, err := strategy(req)
+			if err != nil {
+				deliver(PageResult{Err: err})
+				return
+			}
+			url = ""
+			if next != "" {
+				url = resolveURL(req.responseBase(), next)
+			}
+		}
+	}()
+	return ch
+}
+
+// PaginateItems fetches successive pages like Paginate and collects the
+// items found in each page's JSON response at itemsPath (see JSONGet),
+// or the whole response if itemsPath is empty. opts may be nil.
+func PaginateItems[T any](ctx context.Context, c *Client, method, url, itemsPath string, opts *PaginateOptions) ([]T, error) {
+	var all []T
+	err := StreamItems(ctx, c, method, url, itemsPath, opts, func(items []T) error {
+		all = append(all, items...)
+		return nil
+	})
+	return all, err
+}
+
+// StreamItems fetches successive pages like Paginate and calls fn with
+// the items found in each page's JSON response at itemsPath (see
+// JSONGet), or the whole response if itemsPath is empty. This allows
+// large collections to be processed without holding them in memory. If
+// fn returns an error, pagination stops and the error is returned. opts
+// may be nil.
+func StreamItems[T any](ctx context.Context, c *Client, method, url, itemsPath string, opts *PaginateOptions, fn func(items []T) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // Stops Paginate when returning early
+
+	for page := range c.Paginate(ctx, method, url, opts) {
+		if page.Err != nil {
+			return page.Err
+		}
+
+		var items []T
+		if itemsPath == "" {
+			if err := page.Request.JSON(&items); err != nil {
+				return err
+			}
+		} else {
+			v, err := page.Request.JSONGet(itemsPath)
+			if err != nil && err != ErrJSONPathNotFound {
+				return err
+			}
+			b, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(b, &items); err != nil {
+				return err
+			}
+		}
+
+		if err := fn(items); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// resolveURL resolves ref relative to base.
+func resolveURL(base, ref string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// setQuery returns rawURL with the query parameter key set to value.
+func setQuery(rawURL, key, value string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
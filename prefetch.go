@@ -0,0 +1,138 @@
+package xhr
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// PrefetchOptions configures Prefetch.
+type PrefetchOptions struct {
+	// CacheName, if set, stores the responses in the Cache Storage cache
+	// with that name. Otherwise the responses only prime the HTTP cache,
+	// subject to their caching headers.
+	CacheName string
+
+	// Concurrency is the maximum number of simultaneous fetches. It
+	// defaults to 2.
+	Concurrency int
+}
+
+// prefetching holds the urls currently being prefetched.
+var prefetching = struct {
+	sync.Mutex
+	urls map[string]bool
+}{urls: map[string]bool{}}
+
+// Prefetch fetches urls in the background at low priority so that the
+// data for likely next routes is already cached. urls that are already
+// being prefetched are skipped. Prefetch returns immediately and failures
+// are ignored. opts may be nil.
+func Prefetch(urls []string, opts *PrefetchOptions) {
+	var o PrefetchOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = 2
+	}
+
+	var todo []string
+	prefetching.Lock()
+	for _, url := range urls {
+		if !prefetching.urls[url] {
+			prefetching.urls[url] = true
+			todo = append(todo, url)
+		}
+	}
+	prefetching.Unlock()
+
+	sem := make(chan struct{}, o.Concurrency)
+	for _, url := range todo {
+		url := url
+		go func() {
+			sem <- struct{}{}
+			defer func() {
+				<-sem
+				prefetching.Lock()
+				delete(prefetching.urls, url)
+				prefetching.Unlock()
+			}()
+			prefetch(url, o.CacheName)
+		}()
+	}
+}
+
+func prefetch(url, cacheName string) {
+	if cacheName != "" && js.Global.Get("caches") != js.Undefined {
+		cache, err := await(js.Global.Get("caches").Call("open", cacheName))
+		if err == nil {
+			await(cache.Call("add", js.Global.Get("Request").New(url, js.M{"priority": "low"})))
+			return
+		}
+	}
+
+	if js.Global.Get("fetch") != js.Undefined {
+		resp, err := await(js.Global.Call("fetch", url, js.M{"priority": "low"}))
+		if err == nil {
+			await(resp.Call("arrayBuffer")) // Consume the body so it is fully cached
+		}
+		return
+	}
+	Send(context.Background(), "GET", url, nil)
+}
+
+// PrefetchOnHover prefetches the href of links when the user hovers over
+// them or starts touching them, reducing perceived navigation latency.
+// links can be a CSS selector string, a single element, or a NodeList or
+// array of elements, as *js.Object or js/dom elements. Requests go
+// through Prefetch, so repeated hovers don't cause duplicate fetches.
+// opts may be nil.
+//
+// The returned function removes the listeners.
+func PrefetchOnHover(links interface{}, opts *PrefetchOptions) (unbind func()) {
+	var els []*js.Object
+	switch l := links.(type) {
+	case string:
+		list := js.Global.Get("document").Call("querySelectorAll", l)
+		for i := 0; i < list.Length(); i++ {
+			els = append(els, list.Index(i))
+		}
+	case []*js.Object:
+		els = l
+	default:
+		o := underlying(links)
+		if o == nil {
+			panic("links must be a selector, *js.Object or js/dom element")
+		}
+		if o.Get("length") != js.Undefined {
+			for i := 0; i < o.Length(); i++ {
+				els = append(els, o.Index(i))
+			}
+		} else {
+			els = append(els, o)
+		}
+	}
+
+	seen := map[string]bool{}
+	handler := func(e *js.Object) {
+		href := e.Get("currentTarget").Get("href")
+		if href == js.Undefined || href.String() == "" || seen[href.String()] {
+			return
+		}
+		seen[href.String()] = true
+		Prefetch([]string{href.String()}, opts)
+	}
+
+	for _, el := range els {
+		el.Call("addEventListener", "mouseenter", handler, js.M{"passive": true})
+		el.Call("addEventListener", "touchstart", handler, js.M{"passive": true})
+	}
+	return func() {
+		for _, el := range els {
+			el.Call("removeEventListener", "mouseenter", handler)
+			el.Call("removeEventListener", "touchstart", handler)
+		}
+	}
+}
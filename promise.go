@@ -0,0 +1,157 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// SendPromise sends the request and returns a native JavaScript Promise,
+// allowing Go-implemented clients to be consumed from surrounding
+// JavaScript code.
+//
+// The Promise resolves with an object containing status, statusText,
+// headers, response and responseURL. It rejects with an Error whose
+// message is the Go error's and whose name is one of:
+//
+//	AbortError             the request was aborted or canceled
+//	TimeoutError           the request timed out
+//	NetworkError           ErrNetwork or another ErrFailure
+//	CORSError              ErrCORSBlocked
+//	HTTPError              a *StatusError, with status and statusText set
+//	ResponseTooLargeError  ErrResponseTooLarge
+//	ChecksumError          ErrChecksumMismatch
+//	Error                  any other error, such as from a Validator
+func (r *Request) SendPromise(data interface{}) *js.Object {
+	return r.promise(func() error {
+		return r.Send(context.Background(), data)
+	})
+}
+
+// JSHandle returns a JavaScript object that lets surrounding JavaScript
+// code drive the request:
+//
+//	const res = await handle.promise(); // Sends the request
+//	handle.abort();                     // Aborts it
+//
+// promise sends the request on its first call and returns the same
+// Promise, which settles like the one returned by SendPromise, on every
+// call. The underlying XMLHttpRequest is available as handle.xhr.
+func (r *Request) JSHandle(ctx context.Context, data interface{}) *js.Object {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var p *js.Object
+	h := js.Global.Get("Object").New()
+	h.Set("promise", func() *js.Object {
+		if p == nil {
+			p = r.promise(func() error {
+				defer cancel()
+				return r.Send(ctx, data)
+			})
+		}
+		return p
+	})
+	h.Set("abort", func() {
+		cancel()
+	})
+	h.Set("xhr", r.Object)
+	return h
+}
+
+// promise returns a Promise that settles once send returns.
+func (r *Request) promise(send func() error) *js.Object {
+	return js.Global.Get("Promise").New(func(resolve, reject *js.Object) {
+		go func() {
+			err := send()
+			if err != nil {
+				reject.Invoke(jsError(err))
+				return
+			}
+			resolve.Invoke(r.jsResult())
+		}()
+	})
+}
+
+// jsResult returns a plain JavaScript object describing the response.
+func (r *Request) jsResult() *js.Object {
+	o := js.Global.Get("Object").New()
+	o.Set("status", r.Status)
+	o.Set("statusText", r.StatusText)
+	o.Set("headers", r.ResponseHeaders())
+	o.Set("response", r.Response)
+	o.Set("responseURL", r.Get("responseURL"))
+	return o
+}
+
+// jsError converts an error returned by Send into a JavaScript Error.
+func jsError(err error) *js.Object {
+	e := js.Global.Get("Error").New(err.Error())
+	var se *StatusError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, ErrAborted):
+		e.Set("name", "AbortError")
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, ErrTimeout):
+		e.Set("name", "TimeoutError")
+	case errors.As(err, &se):
+		e.Set("name", "HTTPError")
+		e.Set("status", se.StatusCode())
+		e.Set("statusText", se.StatusText())
+	case errors.Is(err, ErrCORSBlocked):
+		e.Set("name", "CORSError")
+	case errors.Is(err, ErrFailure):
+		e.Set("name", "NetworkError")
+	case errors.Is(err, ErrResponseTooLarge):
+		e.Set("name", "ResponseTooLargeError")
+	case errors.Is(err, ErrChecksumMismatch):
+		e.Set("name", "ChecksumError")
+	}
+	return e
+}
+
+// ExposeToJS registers a global JavaScript object called name with get,
+// post, put and delete methods. Each method sends a request through the
+// client, including its middleware, and returns a Promise like
+// SendPromise does. This allows mixed Go/JS codebases to share one HTTP
+// stack.
+//
+// The methods have the signature (url, data, headers), where data and
+// headers are optional:
+//
+//	api.post("/users", JSON.stringify(user), {"Content-Type": "application/json"})
+func (c *Client) ExposeToJS(name string) {
+	o := js.Global.Get("Object").New()
+	for _, method := range []string{"GET", "POST", "PUT", "DELETE"} {
+		method := method
+		o.Set(strings.ToLower(method), func(url string, data, headers *js.Object) *js.Object {
+			req := c.NewRequest(method, url)
+			if headers != js.Undefined && headers != nil {
+				for _, key := range js.Keys(headers) {
+					req.SetRequestHeader(key, headers.Get(key).String())
+				}
+			}
+			return req.promise(func() error {
+				return c.Do(context.Background(), req, data)
+			})
+		})
+	}
+	js.Global.Set(name, o)
+}
+
+// await blocks until the JavaScript Promise p settles. A rejection is
+// returned as a *js.Error.
+func await(p *js.Object) (*js.Object, error) {
+	type settled struct {
+		value *js.Object
+		err   error
+	}
+	ch := make(chan settled, 1) // Buffered so that the callbacks never block
+	p.Call("then", func(value *js.Object) {
+		ch <- settled{value: value}
+	}, func(reason *js.Object) {
+		ch <- settled{err: &js.Error{Object: reason}}
+	})
+	s := <-ch
+	return s.value, s.err
+}
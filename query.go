@@ -0,0 +1,125 @@
+package xhr
+
+import (
+	"errors"
+
+	"github.com/gopherjs/gopherjs/js"
+	"honnef.co/go/js/dom"
+)
+
+// ErrNoDocument is returned by the query helpers when the response is
+// not a Document.
+var ErrNoDocument = errors.New("response is not a document")
+
+// orderedSnapshot is XPathResult.ORDERED_NODE_SNAPSHOT_TYPE.
+const orderedSnapshot = 7
+
+// document returns the response as a Document. It prefers ResponseXML
+// and falls back to Response for ResponseType Document.
+func (r *Request) document() (*js.Object, error) {
+	if r.ResponseType == "" || r.ResponseType == Document {
+		if doc := r.ResponseXML; doc != nil && doc != js.Undefined {
+			return doc, nil
+		}
+	}
+	if r.ResponseType == Document && r.Response != nil {
+		return r.Response, nil
+	}
+	return nil, ErrNoDocument
+}
+
+// Document returns the response document, parsed by the browser when
+// ResponseType is Document or the response is XML, as a typed
+// honnef.co/go/js/dom Document. HTML documents are returned as
+// dom.HTMLDocument.
+func (r *Request) Document() (dom.Document, error) {
+	doc, err := r.document()
+	if err != nil {
+		return nil, err
+	}
+	return dom.WrapDocument(doc), nil
+}
+
+// XPath evaluates expr against the response document and returns the
+// text content of every matching node.
+func (r *Request) XPath(expr string) ([]string, error) {
+	doc, err := r.document()
+	if err != nil {
+		return nil, err
+	}
+	return XPath(doc, expr)
+}
+
+// XPathString evaluates expr against the response document and returns
+// the result converted to a string, e.g. for "string(//title)".
+func (r *Request) XPathString(expr string) (string, error) {
+	doc, err := r.document()
+	if err != nil {
+		return "", err
+	}
+	var s string
+	err = catch(func() {
+		s = doc.Call("evaluate", expr, doc, nil, 2, nil).Get("stringValue").String() // STRING_TYPE
+	})
+	return s, err
+}
+
+// QuerySelectorAll returns the text content of every element of the
+// response document matching the CSS selector sel.
+func (r *Request) QuerySelectorAll(sel string) ([]string, error) {
+	doc, err := r.document()
+	if err != nil {
+		return nil, err
+	}
+	return QuerySelectorAll(doc, sel)
+}
+
+// XPath evaluates expr against the Document or Node n and returns the
+// text content of every matching node.
+func XPath(n *js.Object, expr string) ([]string, error) {
+	var out []string
+	err := catch(func() {
+		doc := n.Get("ownerDocument")
+		if doc == nil {
+			doc = n // n is a Document
+		}
+		res := doc.Call("evaluate", expr, n, nil, orderedSnapshot, nil)
+		l := res.Get("snapshotLength").Int()
+		out = make([]string, 0, l)
+		for i := 0; i < l; i++ {
+			out = append(out, res.Call("snapshotItem", i).Get("textContent").String())
+		}
+	})
+	return out, err
+}
+
+// QuerySelectorAll returns the text content of every element matching
+// the CSS selector sel within the Document or Element n.
+func QuerySelectorAll(n *js.Object, sel string) ([]string, error) {
+	var out []string
+	err := catch(func() {
+		list := n.Call("querySelectorAll", sel)
+		l := list.Length()
+		out = make([]string, 0, l)
+		for i := 0; i < l; i++ {
+			out = append(out, list.Index(i).Get("textContent").String())
+		}
+	})
+	return out, err
+}
+
+// catch calls fn, converting thrown JavaScript exceptions, such as those
+// caused by invalid expressions, into errors.
+func catch(fn func()) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			jsErr, ok := e.(*js.Error)
+			if !ok {
+				panic(e)
+			}
+			err = errors.New(jsErr.Get("message").String())
+		}
+	}()
+	fn()
+	return nil
+}
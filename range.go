@@ -0,0 +1,122 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidContentRange is returned by ResponseContentRange when the
+// Content-Range header is missing or malformed.
+var ErrInvalidContentRange = errors.New("invalid Content-Range")
+
+// ContentRange is a parsed Content-Range header.
+type ContentRange struct {
+	// Start and End are the inclusive byte offsets of the part.
+	Start, End int64
+
+	// Size is the complete size of the resource, or -1 if unknown.
+	Size int64
+}
+
+// SetRange requests the bytes from start to end, inclusive. If end is
+// negative, the bytes from start to the end of the resource are
+// requested.
+func (r *Request) SetRange(start, end int64) {
+	r.SetRequestHeader("Range", formatRange(start, end))
+}
+
+func formatRange(start, end int64) string {
+	if end < 0 {
+		return fmt.Sprintf("bytes=%d-", start)
+	}
+	return fmt.Sprintf("bytes=%d-%d", start, end)
+}
+
+// ResponseContentRange parses the Content-Range header of a 206 Partial
+// Content response.
+func (r *Request) ResponseContentRange() (ContentRange, error) {
+	return ParseContentRange(r.ResponseHeader("Content-Range"))
+}
+
+// ParseContentRange parses a Content-Range header value of the form
+// "bytes 0-499/1234" or "bytes 0-499/*".
+func ParseContentRange(value string) (ContentRange, error) {
+	cr := ContentRange{Size: -1}
+	if !strings.HasPrefix(value, "bytes ") {
+		return cr, ErrInvalidContentRange
+	}
+	value = strings.TrimSpace(value[len("bytes "):])
+
+	slash := strings.IndexByte(value, '/')
+	dash := strings.IndexByte(value, '-')
+	if slash < 0 || dash < 0 || dash > slash {
+		return cr, ErrInvalidContentRange
+	}
+
+	var err error
+	if cr.Start, err = strconv.ParseInt(value[:dash], 10, 64); err != nil {
+		return cr, ErrInvalidContentRange
+	}
+	if cr.End, err = strconv.ParseInt(value[dash+1:slash], 10, 64); err != nil || cr.End < cr.Start {
+		return cr, ErrInvalidContentRange
+	}
+	if size := value[slash+1:]; size != "*" {
+		if cr.Size, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return cr, ErrInvalidContentRange
+		}
+	}
+	return cr, nil
+}
+
+// ContinueDownload fetches the remainder of a resource of which partial
+// holds the first bytes, and returns the complete contents. It can be
+// used to resume a download that was interrupted by a network failure.
+//
+// If etag is not empty, it is sent as If-Range, so that the server sends
+// the complete resource if it has changed since partial was fetched.
+// Servers that don't support ranges also send the complete resource. In
+// both cases, partial is discarded.
+//
+// A status code other than 200 and 206 is returned as a *StatusError.
+func ContinueDownload(ctx context.Context, c *Client, url string, partial []byte, etag string) ([]byte, error) {
+	if c == nil {
+		c = &Client{}
+	}
+	req := c.NewRequest("GET", url)
+	req.ResponseType = ArrayBuffer
+	req.SetRange(int64(len(partial)), -1)
+	if etag != "" {
+		req.SetRequestHeader("If-Range", etag)
+	}
+	if err := c.Do(ctx, req, nil); err != nil {
+		return nil, err
+	}
+
+	switch req.Status {
+	case http.StatusOK:
+		return req.ResponseArrayBufferBytes(false), nil
+	case http.StatusPartialContent:
+		cr, err := req.ResponseContentRange()
+		if err != nil {
+			return nil, err
+		}
+		if cr.Start != int64(len(partial)) {
+			return nil, fmt.Errorf("%w: expected offset %d, got %d", ErrInvalidContentRange, len(partial), cr.Start)
+		}
+		body := req.ResponseBytes()
+		b := make([]byte, len(partial)+len(body)) // Don't write into spare capacity of partial
+		copy(b, partial)
+		copy(b[len(partial):], body)
+		return b, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// partial already holds the complete resource.
+		if cr, err := req.ResponseContentRange(); err == nil && cr.Size == int64(len(partial)) {
+			return partial, nil
+		}
+	}
+	return nil, NewStatusError(req)
+}
@@ -0,0 +1,196 @@
+package xhr
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rocketlaunchr/react/forks/encoding/json"
+)
+
+// Resource provides the common CRUD operations for a REST collection
+// whose items of type T are encoded as JSON:
+//
+//	users := xhr.NewResource[User](client, "/users")
+//	u, err := users.Get(ctx, "42")
+type Resource[T any] struct {
+	Client *Client
+	Path   string
+}
+
+// NewResource returns a Resource for the collection at path. Item urls
+// are formed by appending "/" and the id to path.
+func NewResource[T any](c *Client, path string) *Resource[T] {
+	if c == nil {
+		c = &Client{}
+	}
+	return &Resource[T]{Client: c, Path: strings.TrimSuffix(path, "/")}
+}
+
+// ListOptions configures Resource.List.
+type ListOptions struct {
+	// Query contains additional query parameters such as filters.
+	Query url.Values
+
+	// Page and PerPage are sent as the "page" and "per_page" query
+	// parameters when greater than zero.
+	Page    int
+	PerPage int
+}
+
+// Page is a single page of a collection.
+type Page[T any] struct {
+	Items []T
+
+	// Next is the url of the next page as advertised by the Link header,
+	// resolved against the url of the response like Request.Next. It is
+	// empty on the last page or when the server does not paginate.
+	Next string
+}
+
+// List fetches the collection. opts may be nil.
+func (r *Resource[T]) List(ctx context.Context, opts *ListOptions) (*Page[T], error) {
+	q := url.Values{}
+	if opts != nil {
+		for k, v := range opts.Query {
+			q[k] = v
+		}
+		if opts.Page > 0 {
+			q.Set("page", strconv.Itoa(opts.Page))
+		}
+		if opts.PerPage > 0 {
+			q.Set("per_page", strconv.Itoa(opts.PerPage))
+		}
+	}
+
+	u := r.Path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+
+	page := &Page[T]{}
+	req, err := sendJSON(ctx, r.Client, "GET", u, nil, &page.Items)
+	if err != nil {
+		return nil, err
+	}
+	page.Next = req.Next()
+	return page, nil
+}
+
+// Get fetches the item with the given id.
+func (r *Resource[T]) Get(ctx context.Context, id string) (T, error) {
+	var v T
+	_, err := sendJSON(ctx, r.Client, "GET", r.itemURL(id), nil, &v)
+	return v, err
+}
+
+// Create adds v to the collection and returns the item created by the
+// server.
+func (r *Resource[T]) Create(ctx context.Context, v T) (T, error) {
+	var out T
+	_, err := sendJSON(ctx, r.Client, "POST", r.Path, v, &out)
+	return out, err
+}
+
+// Update replaces the item with the given id and returns the item
+// stored by the server.
+func (r *Resource[T]) Update(ctx context.Context, id string, v T) (T, error) {
+	var out T
+	_, err := sendJSON(ctx, r.Client, "PUT", r.itemURL(id), v, &out)
+	return out, err
+}
+
+// Delete removes the item with the given id.
+func (r *Resource[T]) Delete(ctx context.Context, id string) error {
+	_, err := sendJSON(ctx, r.Client, "DELETE", r.itemURL(id), nil, nil)
+	return err
+}
+
+func (r *Resource[T]) itemURL(id string) string {
+	return r.Path + "/" + url.PathEscape(id)
+}
+
+// sendJSON sends in, if not nil, as JSON through c and decodes a non-empty
+// response into out, if not nil. A status code other than 2xx is treated
+// as an error.
+func sendJSON(ctx context.Context, c *Client, method, url string, in, out interface{}) (*Request, error) {
+	req := c.NewRequest(method, url)
+	req.ResponseType = Text
+	req.SetRequestHeader("Accept", ApplicationJSON)
+
+	var data interface{}
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		data = string(b)
+		req.SetRequestHeader("Content-Type", ApplicationJSON)
+	}
+
+	err := c.Do(ctx, req, data)
+	if err != nil {
+		return nil, err
+	}
+	if !req.IsStatus2xx() {
+		return req, NewStatusError(req)
+	}
+	if out != nil && req.ResponseText != "" {
+		err = json.Unmarshal(req.ResponseBytes(), out)
+		if err != nil {
+			return req, err
+		}
+	}
+	return req, nil
+}
+
+// parseLinkHeader parses an RFC 5988 Link header into a map from
+// relation type to url. Targets are delimited by angle brackets rather
+// than split on commas, since urls may contain commas.
+func parseLinkHeader(header string) map[string]string {
+	links := map[string]string{}
+	for len(header) > 0 {
+		start := strings.IndexByte(header, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(header[start:], '>')
+		if end < 0 {
+			break
+		}
+		end += start
+		target := header[start+1 : end]
+
+		header = header[end+1:]
+		i := linkParamsEnd(header)
+		for _, param := range strings.Split(header[:i], ";") {
+			name, value, ok := strings.Cut(param, "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+				links[strings.ToLower(rel)] = target
+			}
+		}
+		header = header[i:]
+	}
+	return links
+}
+
+// linkParamsEnd returns the index of the comma that ends the parameters
+// of a link, ignoring commas within quoted values, or len(s).
+func linkParamsEnd(s string) int {
+	quoted := false
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
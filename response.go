@@ -0,0 +1,109 @@
+package xhr
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Response holds the outcome of a completed request.
+type Response struct {
+	// Request is the request that produced the response. It can be used
+	// to access details not captured here.
+	Request *Request
+
+	Status     int
+	StatusText string
+	Header     http.Header
+	Body       []byte
+
+	// URL is the final URL of the response, after redirects.
+	URL string
+
+	// Duration is the time from sending the request until the response
+	// was complete.
+	Duration time.Duration
+
+	pooled *[]byte // Backing buffer of Body when obtained from bufferPool
+}
+
+// bufferPool holds buffers for Response bodies of requests with
+// PoolBuffers set.
+var bufferPool sync.Pool
+
+// newResponse captures the response of a completed request.
+func newResponse(r *Request) *Response {
+	resp := &Response{
+		Request:    r,
+		Status:     r.Status,
+		StatusText: r.StatusText,
+		Header:     r.ResponseHeaderMap(),
+		URL:        r.Get("responseURL").String(),
+		Duration:   r.duration,
+	}
+
+	if r.PoolBuffers && r.ResponseType == ArrayBuffer {
+		b := r.ResponseArrayBufferBytes(true)
+		buf, _ := bufferPool.Get().(*[]byte)
+		if buf == nil || cap(*buf) < len(b) {
+			buf = new([]byte)
+			*buf = make([]byte, 0, len(b))
+		}
+		*buf = append((*buf)[:0], b...)
+		resp.Body = *buf
+		resp.pooled = buf
+		return resp
+	}
+
+	resp.Body = r.ResponseBytes()
+	return resp
+}
+
+// Release returns the buffer backing Body to the pool when the request
+// had PoolBuffers set. Body must not be used afterwards.
+func (resp *Response) Release() {
+	if resp.pooled != nil {
+		bufferPool.Put(resp.pooled)
+		resp.pooled = nil
+	}
+	resp.Body = nil
+}
+
+// Do constructs a new Request, sends it and returns the response. The
+// response body is read as binary data. data is optional and accepts the
+// same types as Request.Send.
+//
+// Unlike Send, Do gives access to the status code, headers and other
+// details of the response without having to use NewRequest. Like Send,
+// only errors of the network layer are treated as errors, unless
+// FailOnError is set.
+func Do(ctx context.Context, method, url string, data interface{}) (*Response, error) {
+	xhr := NewRequest(method, url)
+	xhr.ResponseType = ArrayBuffer
+	xhr.FailOnError = FailOnError
+	err := xhr.Send(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(xhr), nil
+}
+
+// Get is a shorthand for Do with the "GET" method.
+func Get(ctx context.Context, url string) (*Response, error) {
+	return Do(ctx, "GET", url, nil)
+}
+
+// Post is like Do with the "POST" method, setting the "Content-Type"
+// header to contentType.
+func Post(ctx context.Context, url, contentType string, data interface{}) (*Response, error) {
+	xhr := NewRequest("POST", url)
+	xhr.ResponseType = ArrayBuffer
+	xhr.FailOnError = FailOnError
+	xhr.SetRequestHeader("Content-Type", contentType)
+	err := xhr.Send(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(xhr), nil
+}
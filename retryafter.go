@@ -0,0 +1,60 @@
+package xhr
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseRetryAfter parses the value of a Retry-After header, given either
+// as a number of seconds or as an HTTP date, into the duration to wait
+// from now. It returns false if value is empty or invalid. Dates in the
+// past yield a duration of zero.
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// RetryAfter returns the duration to wait before retrying, as given by
+// the response's Retry-After header. It returns false if the header is
+// missing or invalid.
+func (r *Request) RetryAfter() (time.Duration, bool) {
+	return ParseRetryAfter(r.ResponseHeader("Retry-After"), time.Now())
+}
+
+// WaitRetryAfter sleeps for the duration given by the response's
+// Retry-After header, typically sent with 429 Too Many Requests and 503
+// Service Unavailable, or until ctx is done. It returns immediately if
+// the header is missing or invalid.
+func (r *Request) WaitRetryAfter(ctx context.Context) error {
+	d, ok := r.RetryAfter()
+	if !ok || d == 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,170 @@
+package xhr
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// SegmentedOptions configures SegmentedDownload.
+type SegmentedOptions struct {
+	// Client sends the requests. It defaults to the zero Client.
+	Client *Client
+
+	// Segments is the number of concurrent range requests. It defaults
+	// to 4.
+	Segments int
+
+	// MinSegmentSize is the smallest size of a segment in bytes. Smaller
+	// resources are fetched with fewer segments, or a single request. It
+	// defaults to 1 MiB.
+	MinSegmentSize int64
+
+	// OnProgress is called with the aggregate progress of all segments.
+	// It is called from event listeners and must not block.
+	OnProgress func(ProgressEvent)
+}
+
+// SegmentedDownload fetches url with several concurrent range requests
+// and reassembles the parts in order, which can speed up the download of
+// large files. opts may be nil.
+//
+// The size of the resource and support for ranges are determined with a
+// "HEAD" request first. If the server doesn't advertise
+// "Accept-Ranges: bytes" and a Content-Length, or ignores the Range
+// header of a segment, the resource is fetched with a single request
+// instead.
+//
+// A status code other than 2xx is returned as a *StatusError.
+func SegmentedDownload(ctx context.Context, url string, opts *SegmentedOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &SegmentedOptions{}
+	}
+	c := opts.Client
+	if c == nil {
+		c = &Client{}
+	}
+	segments := opts.Segments
+	if segments <= 0 {
+		segments = 4
+	}
+	minSize := opts.MinSegmentSize
+	if minSize <= 0 {
+		minSize = 1 << 20
+	}
+
+	head := c.NewRequest("HEAD", url)
+	if err := c.Do(ctx, head, nil); err != nil {
+		return nil, err
+	}
+	if !head.IsStatus2xx() {
+		return nil, NewStatusError(head)
+	}
+	size := head.ContentLength()
+	if size <= 0 || !strings.EqualFold(head.ResponseHeader("Accept-Ranges"), "bytes") {
+		return downloadSingle(ctx, c, url, opts.OnProgress)
+	}
+	if n := size / minSize; n < int64(segments) {
+		segments = int(n)
+	}
+	if segments < 2 {
+		return downloadSingle(ctx, c, url, opts.OnProgress)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		loaded = make([]int64, segments)
+	)
+	progress := func(i int, n int64) {
+		if opts.OnProgress == nil {
+			return
+		}
+		mu.Lock()
+		loaded[i] = n
+		var sum int64
+		for _, l := range loaded {
+			sum += l
+		}
+		mu.Unlock()
+		opts.OnProgress(ProgressEvent{Loaded: sum, Total: size, LengthComputable: true})
+	}
+
+	type result struct {
+		err  error
+		full []byte // Set if the server ignored the Range header
+	}
+	buf := make([]byte, size)
+	results := make(chan result, segments)
+	segSize := size / int64(segments)
+	for i := 0; i < segments; i++ {
+		start, end := int64(i)*segSize, int64(i+1)*segSize-1
+		if i == segments-1 {
+			end = size - 1
+		}
+		go func(i int, start, end int64) {
+			req := c.NewRequest("GET", url)
+			req.ResponseType = ArrayBuffer
+			req.SetRange(start, end)
+			req.OnProgress(func(e ProgressEvent) {
+				progress(i, e.Loaded)
+			})
+			if err := c.Do(ctx, req, nil); err != nil {
+				results <- result{err: err}
+				return
+			}
+			switch req.Status {
+			case http.StatusOK:
+				results <- result{full: req.ResponseArrayBufferBytes(false)}
+				return
+			case http.StatusPartialContent:
+			default:
+				results <- result{err: NewStatusError(req)}
+				return
+			}
+			cr, err := req.ResponseContentRange()
+			if err == nil && (cr.Start != start || cr.End != end) {
+				err = ErrInvalidContentRange
+			}
+			if err != nil {
+				results <- result{err: err}
+				return
+			}
+			if n := copy(buf[start:end+1], req.ResponseBytes()); int64(n) != end-start+1 {
+				results <- result{err: ErrInvalidContentRange}
+				return
+			}
+			results <- result{}
+		}(i, start, end)
+	}
+
+	for i := 0; i < segments; i++ {
+		res := <-results
+		switch {
+		case res.err != nil:
+			return nil, res.err // The deferred cancel aborts the other segments
+		case res.full != nil:
+			return res.full, nil
+		}
+	}
+	return buf, nil
+}
+
+// downloadSingle fetches url with a single request.
+func downloadSingle(ctx context.Context, c *Client, url string, onProgress func(ProgressEvent)) ([]byte, error) {
+	req := c.NewRequest("GET", url)
+	req.ResponseType = ArrayBuffer
+	if onProgress != nil {
+		req.OnProgress(onProgress)
+	}
+	if err := c.Do(ctx, req, nil); err != nil {
+		return nil, err
+	}
+	if !req.IsStatus2xx() {
+		return nil, NewStatusError(req)
+	}
+	return req.ResponseArrayBufferBytes(false), nil
+}
@@ -0,0 +1,150 @@
+package xhr
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rocketlaunchr/react/forks/encoding/json"
+)
+
+// SignedURL tracks the expiry of a signed url, such as those issued by
+// S3, CloudFront or Google Cloud Storage, and transparently refreshes it
+// shortly before it expires.
+type SignedURL struct {
+	// Refresh obtains a fresh signed url, typically from the app's
+	// backend.
+	Refresh func(ctx context.Context) (string, error)
+
+	// Margin is how long before expiry the url is refreshed. It defaults
+	// to 30s.
+	Margin time.Duration
+
+	mu      sync.Mutex
+	url     string
+	expires time.Time
+}
+
+// NewSignedURL returns a SignedURL starting with url.
+func NewSignedURL(url string, refresh func(ctx context.Context) (string, error)) *SignedURL {
+	return &SignedURL{Refresh: refresh, url: url, expires: SignedURLExpiry(url)}
+}
+
+// URL returns a signed url that is not about to expire, refreshing it if
+// necessary.
+func (s *SignedURL) URL(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	margin := s.Margin
+	if margin == 0 {
+		margin = 30 * time.Second
+	}
+	if s.url != "" && (s.expires.IsZero() || time.Until(s.expires) > margin) {
+		return s.url, nil
+	}
+	return s.refresh(ctx)
+}
+
+// Expires returns the expiry of the current url. It is zero if unknown.
+func (s *SignedURL) Expires() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.expires
+}
+
+// Do sends a request to the signed url through c. prepare, if not nil,
+// can configure the request before it is sent. If the server responds
+// with 403 Forbidden, which is how expired signatures are usually
+// reported, the url is refreshed and the request retried once.
+func (s *SignedURL) Do(ctx context.Context, c *Client, method string, data interface{}, prepare func(*Request)) (*Request, error) {
+	for attempt := 0; ; attempt++ {
+		u, err := s.URL(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		req := c.NewRequest(method, u)
+		if prepare != nil {
+			prepare(req)
+		}
+		err = c.Do(ctx, req, data)
+		if err != nil {
+			return nil, err
+		}
+		if req.Status != 403 || attempt == 1 {
+			return req, nil
+		}
+
+		s.mu.Lock()
+		if s.url == u {
+			s.url = "" // Force a refresh
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *SignedURL) refresh(ctx context.Context) (string, error) {
+	u, err := s.Refresh(ctx)
+	if err != nil {
+		return "", err
+	}
+	s.url, s.expires = u, SignedURLExpiry(u)
+	return u, nil
+}
+
+// SignedURLExpiry returns the expiry encoded in a signed url. It
+// understands AWS Signature Version 4 and 2, CloudFront canned and custom
+// policies, and Google Cloud Storage V4 signatures. It returns the zero
+// time if the expiry can't be determined.
+func SignedURLExpiry(rawURL string) time.Time {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return time.Time{}
+	}
+	q := u.Query()
+
+	for _, prefix := range []string{"X-Amz-", "X-Goog-"} {
+		date, expires := q.Get(prefix+"Date"), q.Get(prefix+"Expires")
+		if date == "" || expires == "" {
+			continue
+		}
+		t, err1 := time.Parse("20060102T150405Z", date)
+		secs, err2 := strconv.Atoi(expires)
+		if err1 == nil && err2 == nil {
+			return t.Add(time.Duration(secs) * time.Second)
+		}
+	}
+
+	if expires := q.Get("Expires"); expires != "" {
+		if secs, err := strconv.ParseInt(expires, 10, 64); err == nil {
+			return time.Unix(secs, 0)
+		}
+	}
+
+	if policy := q.Get("Policy"); policy != "" {
+		// CloudFront uses a url-safe variant of base64.
+		policy = strings.NewReplacer("-", "+", "_", "=", "~", "/").Replace(policy)
+		b, err := base64.StdEncoding.DecodeString(policy)
+		if err != nil {
+			return time.Time{}
+		}
+		var p struct {
+			Statement []struct {
+				Condition struct {
+					DateLessThan struct {
+						EpochTime int64 `json:"AWS:EpochTime"`
+					}
+				}
+			}
+		}
+		if json.Unmarshal(b, &p) == nil && len(p.Statement) > 0 && p.Statement[0].Condition.DateLessThan.EpochTime > 0 {
+			return time.Unix(p.Statement[0].Condition.DateLessThan.EpochTime, 0)
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,18 @@
+package xhr
+
+import (
+	"net/http"
+)
+
+// SniffContentType returns the media type of the response determined
+// from its first 512 bytes with the algorithm of
+// net/http.DetectContentType. This helps with servers that return
+// "application/octet-stream" for everything. The request's ResponseType
+// must be ArrayBuffer, Text or the default.
+func (r *Request) SniffContentType() string {
+	b := r.ResponseBytes()
+	if len(b) > 512 {
+		b = b[:512]
+	}
+	return http.DetectContentType(b)
+}
@@ -0,0 +1,74 @@
+package xhr
+
+import (
+	"time"
+)
+
+// speedWindow is the period over which the upload speed is averaged.
+const speedWindow = 5 * time.Second
+
+type speedSample struct {
+	at    time.Time
+	bytes int64
+}
+
+// speedMeter computes a rolling average of the transfer speed.
+type speedMeter struct {
+	samples []speedSample
+	total   int64
+}
+
+// add records that n of total bytes have been transferred.
+func (m *speedMeter) add(n, total int64) {
+	now := time.Now()
+	m.samples = append(m.samples, speedSample{at: now, bytes: n})
+	m.total = total
+
+	// Keep the oldest sample within the window, or the newest before
+	// it, so that there always is a baseline.
+	i := 0
+	for i < len(m.samples)-2 && now.Sub(m.samples[i+1].at) >= speedWindow {
+		i++
+	}
+	m.samples = m.samples[i:]
+}
+
+// speed returns the average number of bytes transferred per second.
+func (m *speedMeter) speed() float64 {
+	if len(m.samples) < 2 {
+		return 0
+	}
+	first, last := m.samples[0], m.samples[len(m.samples)-1]
+	d := last.at.Sub(first.at).Seconds()
+	if d <= 0 {
+		return 0
+	}
+	return float64(last.bytes-first.bytes) / d
+}
+
+// eta returns the estimated time until the transfer is complete, or 0 if
+// it is unknown.
+func (m *speedMeter) eta() time.Duration {
+	speed := m.speed()
+	if speed <= 0 || m.total <= 0 || len(m.samples) == 0 {
+		return 0
+	}
+	remaining := m.total - m.samples[len(m.samples)-1].bytes
+	if remaining <= 0 {
+		return 0
+	}
+	return time.Duration(float64(remaining) / speed * float64(time.Second))
+}
+
+// Speed returns the current upload speed in bytes per second, averaged
+// over the last few seconds. It is only measured while the progress is
+// observed with WatchProgress or ProgressChan, and is 0 otherwise.
+func (u *Upload) Speed() float64 {
+	return u.meter.speed()
+}
+
+// ETA returns the estimated time until the upload is complete, based on
+// Speed. It is 0 if it is unknown.
+func (u *Upload) ETA() time.Duration {
+	return u.meter.eta()
+}
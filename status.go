@@ -0,0 +1,118 @@
+package xhr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Sentinel errors matched by a *StatusError with errors.Is.
+var (
+	ErrBadRequest         = errors.New("bad request")         // 400
+	ErrUnauthorized       = errors.New("unauthorized")        // 401
+	ErrForbidden          = errors.New("forbidden")           // 403
+	ErrNotFound           = errors.New("not found")           // 404
+	ErrMethodNotAllowed   = errors.New("method not allowed")  // 405
+	ErrGone               = errors.New("gone")                // 410
+	ErrTooManyRequests    = errors.New("too many requests")   // 429
+	ErrServiceUnavailable = errors.New("service unavailable") // 503
+	ErrClientError        = errors.New("client error")        // Any 4xx
+	ErrServerError        = errors.New("server error")        // Any 5xx
+)
+
+// statusErrors maps status codes to their sentinel errors.
+var statusErrors = map[int]error{
+	http.StatusBadRequest:         ErrBadRequest,
+	http.StatusUnauthorized:       ErrUnauthorized,
+	http.StatusForbidden:          ErrForbidden,
+	http.StatusNotFound:           ErrNotFound,
+	http.StatusMethodNotAllowed:   ErrMethodNotAllowed,
+	http.StatusGone:               ErrGone,
+	http.StatusTooManyRequests:    ErrTooManyRequests,
+	http.StatusServiceUnavailable: ErrServiceUnavailable,
+}
+
+// maxErrorBody is the maximum number of body bytes kept by a StatusError.
+const maxErrorBody = 512
+
+// StatusError is the error returned for responses with an unexpected
+// status code. Use errors.As to inspect it.
+type StatusError struct {
+	code   int
+	text   string
+	header http.Header
+	body   []byte
+}
+
+// NewStatusError captures the status, headers and the beginning of the
+// body of a completed request.
+//
+// The body is only captured if ResponseType is ArrayBuffer, Text or
+// the default.
+func NewStatusError(r *Request) *StatusError {
+	e := &StatusError{
+		code:   r.Status,
+		text:   r.StatusText,
+		header: r.ResponseHeaderMap(),
+	}
+
+	switch r.ResponseType {
+	case "", Text, ArrayBuffer:
+		b := r.ResponseBytes()
+		if len(b) > maxErrorBody {
+			b = b[:maxErrorBody]
+		}
+		e.body = append([]byte(nil), b...)
+	}
+	return e
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status: %d %s", e.code, e.text)
+}
+
+// Is reports whether target is the sentinel error for the status code or
+// its class, so that
+//
+//	errors.Is(err, xhr.ErrNotFound)
+//
+// can be used instead of comparing status codes.
+func (e *StatusError) Is(target error) bool {
+	switch {
+	case target != nil && target == statusErrors[e.code]:
+		return true
+	case target == ErrClientError:
+		return e.code >= 400 && e.code <= 499
+	case target == ErrServerError:
+		return e.code >= 500 && e.code <= 599
+	}
+	return false
+}
+
+// StatusCode returns the status code of the response.
+func (e *StatusError) StatusCode() int {
+	return e.code
+}
+
+// StatusText returns the status text of the response.
+func (e *StatusError) StatusText() string {
+	return e.text
+}
+
+// Header returns the response headers.
+func (e *StatusError) Header() http.Header {
+	return e.header
+}
+
+// Body returns up to the first 512 bytes of the response body.
+func (e *StatusError) Body() []byte {
+	return e.body
+}
+
+// RetryAfter returns the duration to wait before retrying, as given by
+// the response's Retry-After header. It returns false if the header is
+// missing or invalid.
+func (e *StatusError) RetryAfter() (time.Duration, bool) {
+	return ParseRetryAfter(e.header.Get("Retry-After"), time.Now())
+}
@@ -0,0 +1,200 @@
+package xhr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"hash"
+	"io"
+	"sync"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// ErrChecksumMismatch is returned when downloaded data does not match
+// the expected checksum.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// StreamDownload fetches url using the Fetch API and calls fn with each
+// chunk of the body as it arrives. Chunks are not retained, so very large
+// files can be processed within memory limits. If fn returns an error,
+// the download is canceled and the error is returned.
+//
+// A status code other than 2xx is treated as an error.
+func StreamDownload(ctx context.Context, url string, fn func(chunk []byte) error) error {
+	ctrl := js.Global.Get("AbortController").New()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ctrl.Call("abort")
+		case <-stop:
+		}
+	}()
+
+	resp, err := await(js.Global.Call("fetch", url, js.M{"signal": ctrl.Get("signal")}))
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return ErrFailure
+	}
+	if !resp.Get("ok").Bool() {
+		return &StatusError{code: resp.Get("status").Int(), text: resp.Get("statusText").String()}
+	}
+
+	reader := resp.Get("body").Call("getReader")
+	for {
+		res, err := await(reader.Call("read"))
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return ErrFailure
+		}
+		if res.Get("done").Bool() {
+			return nil
+		}
+		if err := fn(res.Get("value").Interface().([]byte)); err != nil {
+			reader.Call("cancel")
+			return err
+		}
+	}
+}
+
+// VerifyDownload streams url into h and returns ErrChecksumMismatch if
+// the resulting sum differs from expected. The checksum is computed
+// incrementally as chunks arrive rather than after buffering the whole
+// file.
+//
+// If w is not nil, every chunk is also written to it. Since the checksum
+// can only be verified at the end, the data written to w must be
+// discarded when an error is returned.
+func VerifyDownload(ctx context.Context, url string, h hash.Hash, expected []byte, w io.Writer) error {
+	err := StreamDownload(ctx, url, func(chunk []byte) error {
+		h.Write(chunk)
+		if w != nil {
+			_, err := w.Write(chunk)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(h.Sum(nil), expected) {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+// SendStreaming sends the request like Send, but calls onChunk with each
+// new portion of the response text as it arrives, while ReadyState is
+// Loading, rather than only once the request is done. This allows
+// long-running endpoints, such as progress logs or comet streams, to be
+// consumed incrementally. The request's ResponseType must be Text or the
+// default.
+//
+// onChunk is called from the calling goroutine, so it may block. If
+// onChunk returns an error, the request is aborted and the error is
+// returned.
+func (r *Request) SendStreaming(ctx context.Context, data interface{}, onChunk func(chunk string) error) error {
+	return streamText(ctx, r, func(ctx context.Context) error {
+		return r.Send(ctx, data)
+	}, onChunk)
+}
+
+// SendStreamingBytes is like SendStreaming for binary responses. The
+// response is read as text with the x-user-defined charset, which maps
+// every byte to a single character, so it works in all browsers. The
+// request's ResponseType must be Text or the default, and the response's
+// MIME type is overridden.
+func (r *Request) SendStreamingBytes(ctx context.Context, data interface{}, onChunk func(chunk []byte) error) error {
+	r.OverrideMimeType("text/plain; charset=x-user-defined")
+	return r.SendStreaming(ctx, data, func(chunk string) error {
+		b := make([]byte, 0, len(chunk))
+		for _, c := range chunk {
+			b = append(b, byte(c)) // x-user-defined maps 0x80-0xFF to U+F780-U+F7FF
+		}
+		return onChunk(b)
+	})
+}
+
+// DoStreaming is like Request.SendStreaming, but sends req through the
+// client's middleware.
+func (c *Client) DoStreaming(ctx context.Context, req *Request, data interface{}, onChunk func(chunk string) error) error {
+	return streamText(ctx, req, func(ctx context.Context) error {
+		return c.Do(ctx, req, data)
+	}, onChunk)
+}
+
+// streamText calls send and feeds the response text of req to onChunk
+// as it arrives.
+func streamText(ctx context.Context, req *Request, send func(ctx context.Context) error, onChunk func(chunk string) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		pending []string
+		offset  int
+	)
+	notify := make(chan struct{}, 1)
+	read := func(*js.Object) {
+		text := req.Get("responseText")
+		n := text.Length()
+		if n <= offset {
+			return
+		}
+		chunk := text.Call("substring", offset).String()
+		offset = n
+
+		mu.Lock()
+		pending = append(pending, chunk)
+		mu.Unlock()
+
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	}
+	l := req.Listen("progress", read)
+	defer l.Remove()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- send(ctx)
+	}()
+
+	var chunkErr error
+	for finished := false; !finished; {
+		select {
+		case <-notify:
+		case err := <-done:
+			if chunkErr != nil {
+				return chunkErr
+			}
+			if err != nil {
+				return err
+			}
+			read(nil)
+			finished = true
+		}
+
+		mu.Lock()
+		chunks := pending
+		pending = nil
+		mu.Unlock()
+
+		for _, chunk := range chunks {
+			if chunkErr == nil {
+				chunkErr = onChunk(chunk)
+				if chunkErr != nil {
+					cancel()
+				}
+			}
+		}
+	}
+	return chunkErr
+}
@@ -0,0 +1,198 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// TabShare coordinates identical GET requests across browser tabs of the
+// same origin using a BroadcastChannel. Only one tab fetches a given url
+// while the other tabs receive the shared result, reducing backend load
+// for users with many tabs open.
+//
+// Coordination is best effort: tabs that request a url at exactly the
+// same moment may both end up fetching it. If the fetching tab is closed
+// before its result is broadcast, the waiting tabs fetch the url
+// themselves once ResultTimeout has elapsed.
+type TabShare struct {
+	// Wait is how long a tab waits for another tab to claim a url before
+	// fetching it itself. It defaults to 50ms.
+	Wait time.Duration
+
+	// ResultTimeout is how long a tab waits for the result of a url
+	// claimed by another tab before fetching it itself. It defaults to
+	// 30s.
+	ResultTimeout time.Duration
+
+	channel *js.Object
+	mu      sync.Mutex
+	flights map[string]*tabFlight
+}
+
+type tabFlight struct {
+	local   bool          // this tab fetches the url
+	claimed chan struct{} // closed when another tab claims the url
+	done    chan struct{} // closed when body and err are available
+	body    []byte
+	err     error
+}
+
+// NewTabShare joins the BroadcastChannel called name. Tabs must use the
+// same name to share results.
+func NewTabShare(name string) *TabShare {
+	s := &TabShare{
+		channel: js.Global.Get("BroadcastChannel").New(name),
+		flights: map[string]*tabFlight{},
+	}
+	s.channel.Set("onmessage", func(e *js.Object) {
+		m := e.Get("data")
+		go s.receive(m.Get("type").String(), m.Get("url").String(), m)
+	})
+	return s
+}
+
+// Get fetches url like the package function Send with a GET method, or
+// waits for another tab that is already fetching it.
+func (s *TabShare) Get(ctx context.Context, url string) ([]byte, error) {
+	s.mu.Lock()
+	f, ok := s.flights[url]
+	if !ok {
+		f = &tabFlight{claimed: make(chan struct{}), done: make(chan struct{})}
+		s.flights[url] = f
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		go s.resolve(url, f)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-f.done:
+		return f.body, f.err
+	}
+}
+
+// Close leaves the BroadcastChannel.
+func (s *TabShare) Close() {
+	s.channel.Call("close")
+}
+
+func (s *TabShare) resolve(url string, f *tabFlight) {
+	wait := s.Wait
+	if wait == 0 {
+		wait = 50 * time.Millisecond
+	}
+
+	resultTimeout := s.ResultTimeout
+	if resultTimeout == 0 {
+		resultTimeout = 30 * time.Second
+	}
+
+	s.post("query", url, nil)
+	select {
+	case <-f.claimed:
+	case <-time.After(wait):
+	}
+
+	s.mu.Lock()
+	select {
+	case <-f.claimed:
+		s.mu.Unlock()
+		// The result will be broadcast by the claiming tab, unless it is
+		// closed or crashes first.
+		select {
+		case <-f.done:
+			return
+		case <-time.After(resultTimeout):
+		}
+		s.mu.Lock()
+		select {
+		case <-f.done:
+			s.mu.Unlock()
+			return
+		default:
+		}
+	default:
+	}
+	f.local = true
+	s.mu.Unlock()
+
+	s.post("claim", url, nil)
+	body, err := Send(context.Background(), "GET", url, nil)
+
+	result := js.M{"body": body}
+	if err != nil {
+		result["error"] = err.Error()
+	}
+	s.post("result", url, result)
+	s.finish(url, f, body, err)
+}
+
+func (s *TabShare) receive(typ, url string, m *js.Object) {
+	s.mu.Lock()
+	f := s.flights[url]
+	s.mu.Unlock()
+
+	if f == nil {
+		return
+	}
+
+	switch typ {
+	case "query":
+		if f.local {
+			s.post("claim", url, nil)
+		}
+	case "claim":
+		s.mu.Lock()
+		if !f.local {
+			select {
+			case <-f.claimed:
+			default:
+				close(f.claimed)
+			}
+		}
+		s.mu.Unlock()
+	case "result":
+		if f.local {
+			return
+		}
+		if msg := m.Get("error"); msg != js.Undefined {
+			err := errors.New(msg.String())
+			for _, known := range []error{ErrNetwork, ErrCORSBlocked, ErrFailure} {
+				if msg.String() == known.Error() {
+					err = known
+				}
+			}
+			s.finish(url, f, nil, err)
+			return
+		}
+		s.finish(url, f, js.Global.Get("Uint8Array").New(m.Get("body")).Interface().([]byte), nil)
+	}
+}
+
+func (s *TabShare) finish(url string, f *tabFlight, body []byte, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.flights[url] != f {
+		return // Already finished
+	}
+	delete(s.flights, url)
+	f.body, f.err = body, err
+	close(f.done)
+}
+
+func (s *TabShare) post(typ, url string, m js.M) {
+	if m == nil {
+		m = js.M{}
+	}
+	m["type"] = typ
+	m["url"] = url
+	s.channel.Call("postMessage", m)
+}
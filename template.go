@@ -0,0 +1,72 @@
+package xhr
+
+import (
+	"net/http"
+	"time"
+)
+
+// Clone returns a new, unsent Request with the same configuration as r:
+// method, url, request headers, ResponseType, WithCredentials, the
+// options passed to Open and the Go-side options such as Timeout and
+// FailOnError. Listeners are not cloned.
+//
+// A Request that was created with NewLazyRequest and not opened yet is
+// cloned without being opened.
+func (r *Request) Clone() *Request {
+	c := &Request{
+		PoolBuffers:       r.PoolBuffers,
+		Timeout:           r.Timeout,
+		HeadersTimeout:    r.HeadersTimeout,
+		MaxResponseBytes:  r.MaxResponseBytes,
+		Decompress:        r.Decompress,
+		Checksum:          r.Checksum,
+		ChecksumAlgorithm: r.ChecksumAlgorithm,
+		FailOnError:       r.FailOnError,
+		SuccessPredicate:  r.SuccessPredicate,
+		Validator:         r.Validator,
+		StrictErrors:      r.StrictErrors,
+
+		method: r.method,
+		url:    r.url,
+		header: r.header.Clone(),
+	}
+	c.init()
+	if r.opened {
+		c.openErr = c.open(r.method, r.url, r.openOpts)
+	}
+	c.ResponseType = r.ResponseType
+	c.WithCredentials = r.WithCredentials
+	return c
+}
+
+// RequestTemplate captures the configuration of requests that are made
+// repeatedly, so that it doesn't have to be rebuilt for each of them.
+//
+//	tmpl := &xhr.RequestTemplate{
+//		Method:       "GET",
+//		URL:          "/api/status",
+//		Header:       http.Header{"Accept": {xhr.ApplicationJSON}},
+//		ResponseType: xhr.JSON,
+//	}
+//	req := tmpl.New()
+type RequestTemplate struct {
+	Method string
+	URL    string
+	Header http.Header
+
+	ResponseType    string
+	WithCredentials bool
+	Timeout         time.Duration
+	FailOnError     bool
+}
+
+// New creates a new Request from the template.
+func (t *RequestTemplate) New() *Request {
+	r := NewRequest(t.Method, t.URL)
+	r.SetRequestHeaders(t.Header)
+	r.ResponseType = t.ResponseType
+	r.WithCredentials = t.WithCredentials
+	r.Timeout = t.Timeout
+	r.FailOnError = t.FailOnError
+	return r
+}
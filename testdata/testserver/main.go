@@ -0,0 +1,85 @@
+// Command testserver serves the endpoints used by the package's tests, so
+// that they can be run in a browser.
+//
+// Compile the tests and start the server:
+//
+//	gopherjs test -c -o xhr.test.js github.com/rocketlaunchr/gopherjs-xhr
+//	go run ./testdata/testserver -js xhr.test.js
+//
+// Then open http://localhost:8080 and read the results in the browser's
+// console.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>gopherjs-xhr tests</title></head>
+<body><script src="/xhr.test.js"></script></body>
+</html>
+`
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	script := flag.String("js", "xhr.test.js", "compiled test script")
+	flag.Parse()
+
+	query := func(r *http.Request, key string) int {
+		n, _ := strconv.Atoi(r.URL.Query().Get(key))
+		return n
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("server") == "" {
+			http.Redirect(w, r, "/?server=http://"+r.Host, http.StatusFound)
+			return
+		}
+		io.WriteString(w, page)
+	})
+	mux.HandleFunc("/xhr.test.js", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, *script)
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	})
+	mux.HandleFunc("/bytes", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("a", query(r, "n")))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Duration(query(r, "ms")) * time.Millisecond)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(query(r, "code"))
+	})
+
+	fmt.Printf("Serving tests on http://%s\n", *addr)
+	log.Fatal(http.ListenAndServe(*addr, cors(mux)))
+}
+
+// cors allows the endpoints to be used from pages of other origins, such
+// as a test page served by another server.
+func cors(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Headers", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "*")
+		if r.Method == http.MethodOptions {
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
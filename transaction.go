@@ -0,0 +1,76 @@
+package xhr
+
+import (
+	"context"
+	"strings"
+)
+
+// TransactionError is returned by Transaction.Do when a step failed and
+// some of the compensations failed too.
+type TransactionError struct {
+	// Err is the error of the failed step.
+	Err error
+
+	// RollbackErrs holds the errors of the failed compensations.
+	RollbackErrs []error
+}
+
+func (e *TransactionError) Error() string {
+	msgs := make([]string, 0, len(e.RollbackErrs))
+	for _, err := range e.RollbackErrs {
+		msgs = append(msgs, err.Error())
+	}
+	return e.Err.Error() + " (rollback failed: " + strings.Join(msgs, "; ") + ")"
+}
+
+// Unwrap returns the error of the failed step.
+func (e *TransactionError) Unwrap() error {
+	return e.Err
+}
+
+// Transaction executes an ordered sequence of dependent requests, such as
+// UI flows that create several related resources. Every step can
+// register a compensating action. When a step fails, the compensations
+// of the preceding steps run in reverse order:
+//
+//	var tx xhr.Transaction
+//	err := tx.Do(ctx, createOrder, deleteOrder)
+//	if err == nil {
+//		err = tx.Do(ctx, chargeCard, refundCard)
+//	}
+type Transaction struct {
+	compensations []func(ctx context.Context) error
+}
+
+// Do runs step. If it succeeds, compensate, which may be nil, is
+// registered. If it fails, the transaction is rolled back and the step's
+// error is returned, wrapped in a *TransactionError if any compensation
+// failed as well.
+func (t *Transaction) Do(ctx context.Context, step, compensate func(ctx context.Context) error) error {
+	err := step(ctx)
+	if err != nil {
+		if errs := t.Rollback(); len(errs) > 0 {
+			return &TransactionError{Err: err, RollbackErrs: errs}
+		}
+		return err
+	}
+	if compensate != nil {
+		t.compensations = append(t.compensations, compensate)
+	}
+	return nil
+}
+
+// Rollback runs all registered compensations in reverse order and
+// returns the errors of those that failed. Compensations are run with a
+// fresh context, since the original one is often the cause of the
+// failure. Every compensation runs at most once.
+func (t *Transaction) Rollback() []error {
+	var errs []error
+	for i := len(t.compensations) - 1; i >= 0; i-- {
+		if err := t.compensations[i](context.Background()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.compensations = nil
+	return errs
+}
@@ -0,0 +1,88 @@
+//go:build js
+
+package xhr
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+func TestNoLeaks(t *testing.T) {
+	url := testServer(t)
+	n := 10000
+	if testing.Short() {
+		n = 200
+	}
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < n; i++ {
+		req := NewRequest("GET", url+"/bytes?n=16")
+		req.ResponseType = ArrayBuffer
+		if err := req.Send(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+		if l := req.ActiveListeners(); l != 0 {
+			t.Fatalf("request %d: %d listeners left", i, l)
+		}
+	}
+	checkGoroutines(t, before)
+}
+
+func BenchmarkSend(b *testing.B) {
+	url := testServer(b)
+	for _, size := range []int{0, 1 << 10, 64 << 10, 1 << 20} {
+		payload := bytes.Repeat([]byte{'a'}, size)
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				req := NewRequest("POST", url+"/echo")
+				req.ResponseType = ArrayBuffer
+				if err := req.Send(context.Background(), payload); err != nil {
+					b.Fatal(err)
+				}
+				if len(req.ResponseBytes()) != size {
+					b.Fatal("short response")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRawXHR is the baseline for BenchmarkSend: the same request
+// made with a bare XMLHttpRequest, so that the difference is the
+// overhead of the package.
+func BenchmarkRawXHR(b *testing.B) {
+	url := testServer(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		done := make(chan struct{}, 1)
+		x := js.Global.Get("XMLHttpRequest").New()
+		x.Call("open", "POST", url+"/echo", true)
+		x.Set("responseType", ArrayBuffer)
+		x.Set("onloadend", func() {
+			done <- struct{}{}
+		})
+		x.Call("send")
+		<-done
+	}
+}
+
+func BenchmarkSendCanceled(b *testing.B) {
+	url := testServer(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		req := NewRequest("GET", url+"/slow?ms=50")
+		if err := req.Send(ctx, nil); err == nil {
+			b.Fatal("expected an error")
+		}
+		cancel()
+	}
+}
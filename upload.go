@@ -0,0 +1,237 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gopherjs/gopherjs/js"
+	"honnef.co/go/js/util"
+)
+
+var (
+	// ErrFileTooLarge is reported when a file exceeds UploadBinding.MaxSize.
+	ErrFileTooLarge = errors.New("file too large")
+	// ErrFileType is reported when a file matches none of UploadBinding.Accept.
+	ErrFileType = errors.New("file type not accepted")
+)
+
+// UploadTask uploads a single File as multipart/form-data.
+type UploadTask struct {
+	// File is the JavaScript File being uploaded.
+	File *js.Object
+
+	URL string
+
+	// FieldName is the name of the form field holding the file.
+	FieldName string
+
+	// Client sends the request. A zero Client is used when nil.
+	Client *Client
+
+	// OnProgress, if set, is called as the file is uploaded. It is called
+	// from an event listener and must not block.
+	OnProgress func(loaded, total int64)
+}
+
+// Name returns the file's name.
+func (t *UploadTask) Name() string {
+	return t.File.Get("name").String()
+}
+
+// Size returns the file's size in bytes.
+func (t *UploadTask) Size() int64 {
+	return t.File.Get("size").Int64()
+}
+
+// Type returns the file's MIME type.
+func (t *UploadTask) Type() string {
+	return t.File.Get("type").String()
+}
+
+// Start uploads the file and blocks until the upload completes. A status
+// code other than 2xx is treated as an error.
+func (t *UploadTask) Start(ctx context.Context) (*Response, error) {
+	c := t.Client
+	if c == nil {
+		c = &Client{}
+	}
+
+	fd := js.Global.Get("FormData").New()
+	fd.Call("append", t.FieldName, t.File, t.Name())
+
+	req := c.NewRequest("POST", t.URL)
+	req.ResponseType = Text
+	if t.OnProgress != nil {
+		l := req.Upload().OnProgress(func(e ProgressEvent) {
+			t.OnProgress(e.Loaded, e.Total)
+		})
+		defer l.Remove()
+	}
+
+	err := c.Do(ctx, req, fd)
+	if err != nil {
+		return nil, err
+	}
+	if !req.IsStatus2xx() {
+		return newResponse(req), NewStatusError(req)
+	}
+	return newResponse(req), nil
+}
+
+// UploadBinding turns files dropped onto an element or picked with an
+// <input type=file> into validated UploadTasks, so that a complete
+// uploader requires no DOM event code.
+type UploadBinding struct {
+	URL string
+
+	// FieldName is the name of the form field holding the file. It
+	// defaults to "file".
+	FieldName string
+
+	// Client sends the requests. A zero Client is used when nil.
+	Client *Client
+
+	// MaxSize is the maximum file size in bytes. It is ignored when zero.
+	MaxSize int64
+
+	// Accept lists the permitted MIME types, such as "image/png" or
+	// "image/*", and file extensions, such as ".pdf". All files are
+	// permitted when empty.
+	Accept []string
+
+	// OnTask is called with a task for every accepted file. The task is
+	// not started.
+	OnTask func(t *UploadTask)
+
+	// OnReject, if set, is called for every file failing validation
+	// with ErrFileTooLarge or ErrFileType.
+	OnReject func(file *js.Object, err error)
+}
+
+// BindDropZone makes files dropped onto the element el available as
+// UploadTasks. el can be a *js.Object or a js/dom element. The returned
+// function removes the bindings.
+func (b *UploadBinding) BindDropZone(el interface{}) (unbind func()) {
+	t := util.EventTarget{Object: underlying(el)}
+
+	dragover := func(e *js.Object) {
+		e.Call("preventDefault") // Required for drop to fire
+	}
+	drop := func(e *js.Object) {
+		e.Call("preventDefault")
+		b.handle(e.Get("dataTransfer").Get("files"))
+	}
+	t.AddEventListener("dragover", false, dragover)
+	t.AddEventListener("drop", false, drop)
+
+	return func() {
+		t.RemoveEventListener("dragover", false, dragover)
+		t.RemoveEventListener("drop", false, drop)
+	}
+}
+
+// BindFileInput makes files picked with the <input type=file> element el
+// available as UploadTasks. el can be a *js.Object or a js/dom element.
+// The returned function removes the binding.
+func (b *UploadBinding) BindFileInput(el interface{}) (unbind func()) {
+	o := underlying(el)
+	t := util.EventTarget{Object: o}
+
+	change := func(*js.Object) {
+		b.handle(o.Get("files"))
+		o.Set("value", "") // Allow picking the same file again
+	}
+	t.AddEventListener("change", false, change)
+
+	return func() {
+		t.RemoveEventListener("change", false, change)
+	}
+}
+
+// InputFiles returns the files selected in an <input type=file>
+// element, including all files of a multiple selection, as FileObjects
+// ready to be uploaded. v can be the element, a change or drop event, or
+// a FileList, either as a *js.Object or a js/dom value.
+//
+//	input.AddEventListener("change", false, func(e dom.Event) {
+//		for _, f := range xhr.InputFiles(e) {
+//			go upload(f)
+//		}
+//	})
+func InputFiles(v interface{}) []*FileObject {
+	o := underlying(v)
+	if o == nil || o == js.Undefined {
+		return nil
+	}
+	switch dt := o.Get("dataTransfer"); {
+	case dt != nil && dt != js.Undefined:
+		o = dt.Get("files")
+	case o.Get("target") != js.Undefined && o.Get("files") == js.Undefined:
+		o = o.Get("target").Get("files")
+	case o.Get("files") != js.Undefined:
+		o = o.Get("files")
+	}
+	if o == nil || o == js.Undefined || o.Get("length") == js.Undefined {
+		return nil
+	}
+
+	files := make([]*FileObject, o.Length())
+	for i := range files {
+		files[i] = WrapFile(o.Index(i))
+	}
+	return files
+}
+
+// handle validates the files of a FileList.
+func (b *UploadBinding) handle(files *js.Object) {
+	if files == nil || files == js.Undefined {
+		return
+	}
+
+	field := b.FieldName
+	if field == "" {
+		field = "file"
+	}
+
+	for i := 0; i < files.Length(); i++ {
+		file := files.Index(i)
+		if err := b.validate(file); err != nil {
+			if b.OnReject != nil {
+				b.OnReject(file, err)
+			}
+			continue
+		}
+		if b.OnTask != nil {
+			b.OnTask(&UploadTask{File: file, URL: b.URL, FieldName: field, Client: b.Client})
+		}
+	}
+}
+
+func (b *UploadBinding) validate(file *js.Object) error {
+	if b.MaxSize > 0 && file.Get("size").Int64() > b.MaxSize {
+		return ErrFileTooLarge
+	}
+	if len(b.Accept) == 0 {
+		return nil
+	}
+
+	name := strings.ToLower(file.Get("name").String())
+	typ := strings.ToLower(file.Get("type").String())
+	for _, accept := range b.Accept {
+		accept = strings.ToLower(accept)
+		switch {
+		case strings.HasPrefix(accept, "."):
+			if strings.HasSuffix(name, accept) {
+				return nil
+			}
+		case strings.HasSuffix(accept, "/*"):
+			if strings.HasPrefix(typ, accept[:len(accept)-1]) {
+				return nil
+			}
+		case accept == typ:
+			return nil
+		}
+	}
+	return ErrFileType
+}
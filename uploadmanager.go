@@ -0,0 +1,254 @@
+package xhr
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// UploadStatus is the state of a FileUpload.
+type UploadStatus int
+
+// The possible values of UploadStatus.
+const (
+	UploadQueued UploadStatus = iota
+	UploadRunning
+	UploadDone
+	UploadFailed
+	UploadCanceled
+)
+
+// FileUpload is an item of an UploadManager.
+type FileUpload struct {
+	File *FileObject
+
+	m       *UploadManager
+	status  UploadStatus
+	sent    int64
+	total   int64
+	retries int
+	resp    *Response
+	err     error
+	cancel  context.CancelFunc
+	run     int       // Incremented for every run, so that stale runs can be ignored
+	retryAt time.Time // Earliest start of an automatic retry
+}
+
+// Status returns the current state of the upload.
+func (u *FileUpload) Status() UploadStatus {
+	u.m.mu.Lock()
+	defer u.m.mu.Unlock()
+	return u.status
+}
+
+// Progress returns the number of bytes sent and the total size.
+func (u *FileUpload) Progress() (sent, total int64) {
+	u.m.mu.Lock()
+	defer u.m.mu.Unlock()
+	return u.sent, u.total
+}
+
+// Response returns the response of a completed upload.
+func (u *FileUpload) Response() *Response {
+	u.m.mu.Lock()
+	defer u.m.mu.Unlock()
+	return u.resp
+}
+
+// Err returns the error of a failed upload.
+func (u *FileUpload) Err() error {
+	u.m.mu.Lock()
+	defer u.m.mu.Unlock()
+	return u.err
+}
+
+// Retry queues a failed or canceled upload again.
+func (u *FileUpload) Retry() {
+	u.m.mu.Lock()
+	if u.status == UploadFailed || u.status == UploadCanceled {
+		u.status = UploadQueued
+		u.sent = 0
+		u.err = nil
+		u.retries = 0
+		u.retryAt = time.Time{}
+	}
+	u.m.mu.Unlock()
+	u.m.notify(u)
+	u.m.schedule()
+}
+
+// Cancel stops a queued or running upload.
+func (u *FileUpload) Cancel() {
+	u.m.mu.Lock()
+	var cancel context.CancelFunc
+	switch u.status {
+	case UploadRunning:
+		cancel = u.cancel
+		fallthrough
+	case UploadQueued:
+		u.status = UploadCanceled
+	}
+	u.m.mu.Unlock()
+
+	if cancel != nil {
+		cancel() // run reschedules once the request has been aborted
+	}
+	u.m.notify(u)
+}
+
+// UploadManager uploads multiple files as multipart/form-data, running a
+// limited number of uploads concurrently in the order they were added.
+type UploadManager struct {
+	// Client sends the requests. A zero Client is used when nil.
+	Client *Client
+
+	URL string
+
+	// FieldName is the name of the form field holding the file. It
+	// defaults to "file".
+	FieldName string
+
+	// Concurrency is the maximum number of simultaneous uploads. It
+	// defaults to 3.
+	Concurrency int
+
+	// MaxRetries is the number of times a failed upload is queued again
+	// automatically, after a delay that grows with every attempt.
+	// Responses with status 4xx other than 408 and 429 are not retried.
+	// Failed uploads can also be retried with Retry.
+	MaxRetries int
+
+	// OnProgress, if set, is called whenever an upload makes progress or
+	// changes state. It is called from event listeners and must not
+	// block.
+	OnProgress func(u *FileUpload)
+
+	mu      sync.Mutex
+	uploads []*FileUpload
+	running int
+}
+
+// Add queues an upload of f.
+func (m *UploadManager) Add(f *FileObject) *FileUpload {
+	u := &FileUpload{File: f, m: m, total: f.Size}
+	m.mu.Lock()
+	m.uploads = append(m.uploads, u)
+	m.mu.Unlock()
+
+	m.schedule()
+	return u
+}
+
+// Uploads returns all uploads added to the manager.
+func (m *UploadManager) Uploads() []*FileUpload {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*FileUpload(nil), m.uploads...)
+}
+
+// Progress returns the aggregate number of bytes sent and total size of
+// all uploads that are not canceled.
+func (m *UploadManager) Progress() (sent, total int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, u := range m.uploads {
+		if u.status != UploadCanceled {
+			sent += u.sent
+			total += u.total
+		}
+	}
+	return sent, total
+}
+
+// schedule starts queued uploads while below the concurrency limit.
+func (m *UploadManager) schedule() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	limit := m.Concurrency
+	if limit <= 0 {
+		limit = 3
+	}
+
+	now := time.Now()
+	for _, u := range m.uploads {
+		if m.running >= limit {
+			return
+		}
+		if u.status != UploadQueued || now.Before(u.retryAt) {
+			continue
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		u.status = UploadRunning
+		u.cancel = cancel
+		u.run++
+		m.running++
+		go m.run(ctx, u, u.run)
+	}
+}
+
+// run performs the run'th run of u. A run that was canceled may complete
+// after a new run has been started by Retry, in which case its outcome is
+// ignored.
+func (m *UploadManager) run(ctx context.Context, u *FileUpload, run int) {
+	field := m.FieldName
+	if field == "" {
+		field = "file"
+	}
+	t := &UploadTask{
+		File:      u.File.Object,
+		URL:       m.URL,
+		FieldName: field,
+		Client:    m.Client,
+		OnProgress: func(sent, total int64) {
+			m.mu.Lock()
+			if u.run != run {
+				m.mu.Unlock()
+				return
+			}
+			u.sent, u.total = sent, total
+			m.mu.Unlock()
+			m.notify(u)
+		},
+	}
+	resp, err := t.Start(ctx)
+
+	m.mu.Lock()
+	m.running--
+	current := u.run == run && u.status == UploadRunning // Otherwise canceled or superseded
+	var retryIn time.Duration
+	if current {
+		u.cancel = nil
+		switch {
+		case err == nil:
+			u.status = UploadDone
+			u.sent = u.total
+			u.resp = resp
+		case u.retries < m.MaxRetries && retryableChunkError(err):
+			u.retries++
+			u.status = UploadQueued
+			u.sent = 0
+			retryIn = time.Duration(u.retries) * time.Second
+			u.retryAt = time.Now().Add(retryIn)
+		default:
+			u.status = UploadFailed
+			u.err = err
+			u.resp = resp
+		}
+	}
+	m.mu.Unlock()
+
+	if current {
+		m.notify(u)
+	}
+	if retryIn > 0 {
+		time.AfterFunc(retryIn, m.schedule)
+	}
+	m.schedule()
+}
+
+func (m *UploadManager) notify(u *FileUpload) {
+	if m.OnProgress != nil {
+		m.OnProgress(u)
+	}
+}
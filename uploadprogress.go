@@ -0,0 +1,105 @@
+package xhr
+
+import (
+	"time"
+)
+
+// UploadProgress describes the progress of an upload.
+type UploadProgress struct {
+	// BytesSent is the number of bytes of the body sent so far.
+	BytesSent int64
+
+	// Total is the size of the body.
+	Total int64
+
+	// Percent is the percentage of the body sent so far, between 0 and
+	// 100.
+	Percent float64
+
+	// BytesPerSecond is the upload speed averaged over the last few
+	// seconds.
+	BytesPerSecond float64
+
+	// ETA is the estimated time until the upload is complete, or 0 if it
+	// is unknown.
+	ETA time.Duration
+}
+
+// KBPerSecond returns the upload speed in kilobytes per second.
+func (p UploadProgress) KBPerSecond() float64 {
+	return p.BytesPerSecond / 1000
+}
+
+func (u *Upload) newUploadProgress(e ProgressEvent) UploadProgress {
+	u.meter.add(e.Loaded, e.Total)
+	p := UploadProgress{
+		BytesSent:      e.Loaded,
+		Total:          e.Total,
+		Percent:        e.Percent(),
+		BytesPerSecond: u.meter.speed(),
+		ETA:            u.meter.eta(),
+	}
+	if p.Percent < 0 {
+		p.Percent = 0
+	}
+	return p
+}
+
+// WatchProgress registers fn to be called as the body is uploaded, and
+// once more when the upload ends, whether it succeeded or not. The
+// listeners are removed automatically when the upload ends.
+//
+// fn is called from an event listener and must not block. WatchProgress
+// must be called before the request is sent.
+func (u *Upload) WatchProgress(fn func(UploadProgress)) {
+	progress := u.OnProgress(func(e ProgressEvent) {
+		fn(u.newUploadProgress(e))
+	})
+	var loadend *Listener
+	loadend = u.OnLoadEnd(func(e ProgressEvent) {
+		progress.Remove()
+		loadend.Remove()
+		fn(u.newUploadProgress(e))
+	})
+}
+
+// ProgressChan is like WatchProgress, but delivers the progress on a
+// channel, which is closed when the upload ends. Only the latest progress
+// is kept if the receiver falls behind, so the channel never blocks the
+// upload.
+//
+// Requests without a body have no upload, so the channel is never
+// closed for them. ProgressChan must be called before the request is
+// sent.
+func (u *Upload) ProgressChan() <-chan UploadProgress {
+	ch := make(chan UploadProgress, 1)
+	send := func(p UploadProgress) {
+		select {
+		case <-ch: // Drop stale progress
+		default:
+		}
+		ch <- p
+	}
+
+	progress := u.OnProgress(func(e ProgressEvent) {
+		send(u.newUploadProgress(e))
+	})
+	var loadend *Listener
+	loadend = u.OnLoadEnd(func(e ProgressEvent) {
+		progress.Remove()
+		loadend.Remove()
+		send(u.newUploadProgress(e))
+		close(ch)
+	})
+	return ch
+}
+
+// BytesSent returns the number of body bytes sent as of the last progress
+// event. It is only tracked if Upload was called before the request was
+// sent.
+func (u *Upload) BytesSent() int64 {
+	if u == nil {
+		return 0
+	}
+	return u.sent
+}
@@ -0,0 +1,84 @@
+package xhr
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestState describes the progress of a request started by
+// UseRequest.
+type RequestState struct {
+	Loading bool
+	Data    *Response
+	Error   error
+}
+
+// UseRequest sends a request through c and reports its state to
+// onChange. It provides useRequest-style data fetching for React
+// components written in Go:
+//
+//	func (c *Items) ComponentDidMount() {
+//		c.refetch, c.cancel = xhr.UseRequest(client, "GET", "/items", nil, func(s xhr.RequestState) {
+//			c.SetState(s)
+//		})
+//	}
+//
+//	func (c *Items) ComponentWillUnmount() {
+//		c.cancel()
+//	}
+//
+// refetch aborts any request in flight and sends a new one. cancel aborts
+// the request in flight and stops all further calls to onChange. It must
+// be called when the component unmounts.
+func UseRequest(c *Client, method, url string, data interface{}, onChange func(RequestState)) (refetch func(), cancel func()) {
+	var (
+		mu        sync.Mutex
+		stop      context.CancelFunc = func() {}
+		gen       int
+		unmounted bool
+	)
+
+	refetch = func() {
+		mu.Lock()
+		if unmounted {
+			mu.Unlock()
+			return
+		}
+		stop()
+		gen++
+		g := gen
+		ctx, cf := context.WithCancel(context.Background())
+		stop = cf
+		mu.Unlock()
+
+		onChange(RequestState{Loading: true})
+
+		go func() {
+			req := c.NewRequest(method, url)
+			err := c.Do(ctx, req, data)
+
+			mu.Lock()
+			current := g == gen && !unmounted
+			mu.Unlock()
+			if !current {
+				return // Superseded or unmounted
+			}
+
+			if err != nil {
+				onChange(RequestState{Error: err})
+				return
+			}
+			onChange(RequestState{Data: newResponse(req)})
+		}()
+	}
+
+	cancel = func() {
+		mu.Lock()
+		defer mu.Unlock()
+		unmounted = true
+		stop()
+	}
+
+	refetch()
+	return refetch, cancel
+}
@@ -0,0 +1,195 @@
+package xhr
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strings"
+)
+
+// WebDAV methods.
+const (
+	MethodPropFind  = "PROPFIND"
+	MethodPropPatch = "PROPPATCH"
+	MethodMkcol     = "MKCOL"
+	MethodMove      = "MOVE"
+	MethodCopy      = "COPY"
+)
+
+// The possible values of the WebDAV Depth header.
+const (
+	Depth0        = "0"
+	Depth1        = "1"
+	DepthInfinity = "infinity"
+)
+
+// MultiStatus is a WebDAV multi-status (207) response.
+type MultiStatus struct {
+	Responses []DAVResponse `xml:"DAV: response"`
+}
+
+// DAVResponse describes a single resource of a MultiStatus.
+type DAVResponse struct {
+	Href     string     `xml:"DAV: href"`
+	Status   string     `xml:"DAV: status"`
+	PropStat []PropStat `xml:"DAV: propstat"`
+}
+
+// PropStat groups properties sharing the same status.
+type PropStat struct {
+	Prop   PropList `xml:"DAV: prop"`
+	Status string   `xml:"DAV: status"`
+}
+
+// PropList is the content of a prop element.
+type PropList struct {
+	Props []Prop `xml:",any"`
+}
+
+// Prop is a single WebDAV property.
+type Prop struct {
+	XMLName xml.Name
+	// Value is the text content of the property.
+	Value string `xml:",chardata"`
+	// InnerXML is the raw content of the property, for structured values
+	// such as resourcetype.
+	InnerXML string `xml:",innerxml"`
+}
+
+// Prop returns the named property if it was returned with a 2xx status.
+func (r *DAVResponse) Prop(name xml.Name) (*Prop, bool) {
+	for _, ps := range r.PropStat {
+		if !strings.Contains(ps.Status, " 2") {
+			continue
+		}
+		for i := range ps.Prop.Props {
+			if ps.Prop.Props[i].XMLName == name {
+				return &ps.Prop.Props[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// IsCollection returns true if the resourcetype property marks the
+// resource as a collection (directory).
+func (r *DAVResponse) IsCollection() bool {
+	p, ok := r.Prop(xml.Name{Space: "DAV:", Local: "resourcetype"})
+	return ok && strings.Contains(p.InnerXML, "collection")
+}
+
+// PropFind retrieves the named properties, or all properties if none are
+// given, of the resource at url and, depending on depth, its members.
+func (c *Client) PropFind(ctx context.Context, url, depth string, props ...xml.Name) (*MultiStatus, error) {
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?><d:propfind xmlns:d="DAV:">`)
+	if len(props) == 0 {
+		body.WriteString(`<d:allprop/>`)
+	} else {
+		body.WriteString(`<d:prop>`)
+		for _, p := range props {
+			writeEmptyElement(&body, p)
+		}
+		body.WriteString(`</d:prop>`)
+	}
+	body.WriteString(`</d:propfind>`)
+
+	req := c.NewRequest(MethodPropFind, url)
+	req.SetRequestHeader("Depth", depth)
+	return c.multiStatus(ctx, req, body.String())
+}
+
+// PropPatch sets and removes properties of the resource at url.
+func (c *Client) PropPatch(ctx context.Context, url string, set map[xml.Name]string, remove []xml.Name) (*MultiStatus, error) {
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="utf-8"?><d:propertyupdate xmlns:d="DAV:">`)
+	if len(set) > 0 {
+		body.WriteString(`<d:set><d:prop>`)
+		for name, value := range set {
+			body.WriteString(`<x:` + name.Local + ` xmlns:x="`)
+			xml.EscapeText(&body, []byte(name.Space))
+			body.WriteString(`">`)
+			xml.EscapeText(&body, []byte(value))
+			body.WriteString(`</x:` + name.Local + `>`)
+		}
+		body.WriteString(`</d:prop></d:set>`)
+	}
+	if len(remove) > 0 {
+		body.WriteString(`<d:remove><d:prop>`)
+		for _, name := range remove {
+			writeEmptyElement(&body, name)
+		}
+		body.WriteString(`</d:prop></d:remove>`)
+	}
+	body.WriteString(`</d:propertyupdate>`)
+
+	return c.multiStatus(ctx, c.NewRequest(MethodPropPatch, url), body.String())
+}
+
+// Mkcol creates a collection at url.
+func (c *Client) Mkcol(ctx context.Context, url string) error {
+	return c.davSend(ctx, c.NewRequest(MethodMkcol, url))
+}
+
+// Move moves the resource at src to dst.
+func (c *Client) Move(ctx context.Context, src, dst string, overwrite bool) error {
+	return c.davSend(ctx, c.destRequest(MethodMove, src, dst, overwrite))
+}
+
+// Copy copies the resource at src to dst. For collections, depth must be
+// Depth0 or DepthInfinity.
+func (c *Client) Copy(ctx context.Context, src, dst, depth string, overwrite bool) error {
+	req := c.destRequest(MethodCopy, src, dst, overwrite)
+	req.SetRequestHeader("Depth", depth)
+	return c.davSend(ctx, req)
+}
+
+func (c *Client) destRequest(method, src, dst string, overwrite bool) *Request {
+	req := c.NewRequest(method, src)
+	// Destination must be an absolute url.
+	req.SetRequestHeader("Destination", resolveURL(locationHref(), c.resolve(dst)))
+	if overwrite {
+		req.SetRequestHeader("Overwrite", "T")
+	} else {
+		req.SetRequestHeader("Overwrite", "F")
+	}
+	return req
+}
+
+// davSend sends req and treats a status code other than 2xx as an error.
+func (c *Client) davSend(ctx context.Context, req *Request) error {
+	err := c.Do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+	if !req.IsStatus2xx() {
+		return NewStatusError(req)
+	}
+	return nil
+}
+
+func (c *Client) multiStatus(ctx context.Context, req *Request, body string) (*MultiStatus, error) {
+	req.ResponseType = Text
+	req.SetRequestHeader("Content-Type", "application/xml; charset=utf-8")
+
+	err := c.Do(ctx, req, body)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != 207 {
+		return nil, NewStatusError(req)
+	}
+
+	ms := &MultiStatus{}
+	err = xml.Unmarshal(req.ResponseBytes(), ms)
+	if err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
+func writeEmptyElement(buf *bytes.Buffer, name xml.Name) {
+	buf.WriteString(`<x:` + name.Local + ` xmlns:x="`)
+	xml.EscapeText(buf, []byte(name.Space))
+	buf.WriteString(`"/>`)
+}
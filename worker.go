@@ -0,0 +1,182 @@
+package xhr
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// WorkerScript is the JavaScript source run by a Worker. NewWorker loads
+// it from a blob url. Sites whose Content Security Policy forbids blob
+// workers can serve it themselves and use NewWorkerFromURL instead.
+const WorkerScript = `"use strict";
+var pending = {};
+self.onmessage = function(e) {
+	var m = e.data;
+	if (m.abort) {
+		if (pending[m.id]) pending[m.id].abort();
+		return;
+	}
+	var x = new XMLHttpRequest();
+	x.onload = function() {
+		delete pending[m.id];
+		self.postMessage({id: m.id, status: x.status, statusText: x.statusText, headers: x.getAllResponseHeaders(), body: x.response}, [x.response]);
+	};
+	x.onerror = function() { delete pending[m.id]; self.postMessage({id: m.id, error: "failure"}); };
+	x.ontimeout = function() { delete pending[m.id]; self.postMessage({id: m.id, error: "timeout"}); };
+	x.onabort = function() { delete pending[m.id]; };
+	try {
+		x.open(m.method, m.url, true);
+		x.responseType = "arraybuffer";
+		for (var i = 0; i < m.headers.length; i += 2) x.setRequestHeader(m.headers[i], m.headers[i + 1]);
+		if (m.timeout > 0) x.timeout = m.timeout;
+		x.withCredentials = m.withCredentials;
+		x.send(m.body);
+		pending[m.id] = x;
+	} catch (err) {
+		self.postMessage({id: m.id, error: "failure"});
+	}
+};
+`
+
+// ErrWorkerTerminated is returned by Worker.Send for requests that are
+// in flight when the worker is terminated, or that are sent afterwards.
+var ErrWorkerTerminated = errors.New("worker terminated")
+
+// Worker performs requests inside a dedicated Web Worker. Response
+// bodies are transferred back to the main thread as ArrayBuffers without
+// copying, which keeps heavy downloads off the main thread.
+//
+// A Worker can be used concurrently and should be closed with Terminate
+// once no longer required.
+type Worker struct {
+	// Header contains headers that are set on every request.
+	Header map[string]string
+
+	// WithCredentials is applied to every request.
+	WithCredentials bool
+
+	worker     *js.Object
+	url        *js.Object // blob url to revoke on Terminate
+	mu         sync.Mutex
+	nextID     int
+	pending    map[int]chan *js.Object
+	terminated bool
+}
+
+// NewWorker starts a new Web Worker running WorkerScript.
+func NewWorker() *Worker {
+	blob := js.Global.Get("Blob").New([]string{WorkerScript}, js.M{"type": "application/javascript"})
+	url := js.Global.Get("URL").Call("createObjectURL", blob)
+	w := NewWorkerFromURL(url.String())
+	w.url = url
+	return w
+}
+
+// NewWorkerFromURL starts a new Web Worker from scriptURL, which must
+// serve WorkerScript.
+func NewWorkerFromURL(scriptURL string) *Worker {
+	w := &Worker{
+		worker:  js.Global.Get("Worker").New(scriptURL),
+		pending: map[int]chan *js.Object{},
+	}
+	w.worker.Set("onmessage", func(e *js.Object) {
+		m := e.Get("data")
+		id := m.Get("id").Int()
+
+		w.mu.Lock()
+		ch := w.pending[id]
+		delete(w.pending, id)
+		w.mu.Unlock()
+
+		if ch != nil {
+			ch <- m // buffered
+		}
+	})
+	return w
+}
+
+// Send performs the request inside the worker. Like the package function
+// Send, the response is returned as is and HTTP status codes 4xx and 5xx
+// are not treated as errors.
+func (w *Worker) Send(ctx context.Context, method, url string, data []byte) ([]byte, error) {
+	ch := make(chan *js.Object, 1)
+
+	w.mu.Lock()
+	if w.terminated {
+		w.mu.Unlock()
+		return nil, ErrWorkerTerminated
+	}
+	w.nextID++
+	id := w.nextID
+	w.pending[id] = ch
+	w.mu.Unlock()
+
+	headers := []string{}
+	for name, value := range w.Header {
+		headers = append(headers, name, value)
+	}
+
+	msg := js.M{
+		"id":              id,
+		"method":          method,
+		"url":             url,
+		"headers":         headers,
+		"withCredentials": w.WithCredentials,
+		"timeout":         0,
+	}
+	if dt, ok := ctx.Deadline(); ok {
+		msg["timeout"] = time.Until(dt) / time.Millisecond
+	}
+
+	if data == nil {
+		w.worker.Call("postMessage", msg)
+	} else {
+		// Copy the payload so that transferring it doesn't detach Go memory.
+		body := js.Global.Get("Uint8Array").New(data)
+		msg["body"] = body
+		w.worker.Call("postMessage", msg, []interface{}{body.Get("buffer")})
+	}
+
+	select {
+	case <-ctx.Done():
+		w.mu.Lock()
+		delete(w.pending, id)
+		w.mu.Unlock()
+		w.worker.Call("postMessage", js.M{"id": id, "abort": true})
+		return nil, ctx.Err()
+	case m := <-ch:
+		if e := m.Get("error"); e != js.Undefined {
+			switch e.String() {
+			case "timeout":
+				return nil, context.DeadlineExceeded
+			case "terminated":
+				return nil, ErrWorkerTerminated
+			}
+			return nil, ErrFailure
+		}
+		return js.Global.Get("Uint8Array").New(m.Get("body")).Interface().([]byte), nil
+	}
+}
+
+// Terminate stops the worker. Requests in flight, and requests sent
+// afterwards, fail with ErrWorkerTerminated.
+func (w *Worker) Terminate() {
+	w.mu.Lock()
+	w.terminated = true
+	for id, ch := range w.pending {
+		reply := js.Global.Get("Object").New()
+		reply.Set("error", "terminated")
+		ch <- reply // buffered
+		delete(w.pending, id)
+	}
+	w.mu.Unlock()
+
+	w.worker.Call("terminate")
+	if w.url != nil {
+		js.Global.Get("URL").Call("revokeObjectURL", w.url)
+	}
+}
@@ -33,11 +33,15 @@
 package xhr
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"time"
 
 	"github.com/gopherjs/gopherjs/js"
-	"github.com/rocketlaunchr/react/forks/context"
 	"honnef.co/go/js/util"
 )
 
@@ -77,7 +81,7 @@ var MultipartFormData = func(boundary string) string {
 
 // Request wraps XMLHttpRequest objects. New instances have to be
 // created with NewRequest. Each instance may only be used for a
-// single request.
+// single request, unless it is prepared for another one with Reset.
 //
 // To create a request that behaves in the same way as the top-level
 // Send function with regard to handling binary data, use the
@@ -86,7 +90,7 @@ var MultipartFormData = func(boundary string) string {
 //   req := xhr.NewRequest("POST", "http://example.com")
 //   req.ResponseType = xhr.ArrayBuffer
 //   req.Send(ctx, []byte("data"))
-//   b := js.Global.Get("Uint8Array").New(req.Response).Interface().([]byte)
+//   b := req.ResponseBytes()
 type Request struct {
 	*js.Object
 	util.EventTarget
@@ -99,40 +103,275 @@ type Request struct {
 	StatusText      string     `js:"statusText"`
 	WithCredentials bool       `js:"withCredentials"`
 
-	alreadySent bool // Indicate that send has been called
+	// PoolBuffers makes Responses created from the request copy
+	// ArrayBuffer bodies into buffers from a shared pool, rather than
+	// sharing the memory of the ArrayBuffer. This reduces allocation
+	// churn for high-frequency polling that needs its own copy of the
+	// body. Response.Release must be called once the body is no longer
+	// used.
+	PoolBuffers bool
+
+	// Timeout limits the duration of the request independently of the
+	// context passed to Send. If it elapses first, the error returned
+	// by Send matches ErrTimeout rather than context.DeadlineExceeded.
+	// Zero means no limit other than the context's deadline.
+	Timeout time.Duration
+
+	// HeadersTimeout limits the time until the response headers are
+	// received. If it elapses first, the request is aborted and the
+	// error returned by Send matches ErrHeadersTimeout. This detects dead servers behind
+	// proxies that keep connections open, without shortening the time
+	// allowed for downloading the body. Zero means no limit.
+	HeadersTimeout time.Duration
+
+	// MaxResponseBytes limits the size of the response body. If the
+	// response is larger, the request is aborted as soon as this is
+	// known and Send returns ErrResponseTooLarge. This protects
+	// memory-constrained tabs from accidentally downloading huge
+	// responses. Zero means no limit.
+	MaxResponseBytes int64
+
+	// Decompress makes Send decompress ArrayBuffer responses that the
+	// browser didn't decode, for example because a proxy mislabeled
+	// their "Content-Encoding". It is one of DecompressGzip,
+	// DecompressDeflate, DecompressDeflateRaw or DecompressAuto. The
+	// decompressed body is returned by ResponseBytes.
+	Decompress string
+
+	// Checksum is the expected digest of an ArrayBuffer response,
+	// computed with ChecksumAlgorithm. If it is set and the response
+	// doesn't match, Send returns ErrChecksumMismatch.
+	Checksum []byte
+
+	// ChecksumAlgorithm is the SubtleCrypto digest algorithm used for
+	// Checksum, such as SHA256 or SHA512. It defaults to SHA256.
+	ChecksumAlgorithm string
+
+	// Validator, if set, checks the response before Decode decodes it.
+	Validator ValidatorFunc
+
+	// FailOnError makes Send return a *StatusError for responses for
+	// which IsSuccess returns false.
+	FailOnError bool
+
+	// SuccessPredicate overrides which status codes IsSuccess considers
+	// successful. It defaults to 2xx status codes.
+	SuccessPredicate func(status int) bool
+
+	// StrictErrors makes misuse of the request, such as sending it
+	// twice, and exceptions thrown by the XMLHttpRequest, such as for
+	// invalid URLs, return errors instead of panicking. Exceptions thrown
+	// by methods without an error result, such as SetRequestHeader with
+	// an invalid header name, are recorded and returned by Send. It
+	// defaults to the package-level StrictErrors.
+	StrictErrors bool
+
+	alreadySent   bool  // Indicate that send has been called
+	abortErr      error // The error Send returns after an abort
+	method        string
+	url           string
+	header        http.Header // Request headers, reapplied by Open
+	opened        bool
+	openErr       error // Returned by Send if opening failed in strict mode
+	callErr       error // First exception of a call without an error result, returned by Send
+	openOpts      *OpenOptions
+	parsedJSON    *js.Object // Cached by jsonRoot
+	upload        *Upload
+	listeners     *listenerSet
+	sendListeners *listenerSet  // Listeners of an in-flight Send
+	duration      time.Duration // Time from send to loadend
+	bodyView      []byte        // Cached by ResponseArrayBufferBytes
 }
 
 // Upload wraps XMLHttpRequestUpload objects.
 type Upload struct {
 	*js.Object
 	util.EventTarget
+
+	listeners *listenerSet
+	meter     speedMeter // Fed by WatchProgress and ProgressChan
+	sent      int64      // Bytes sent as of the last progress event
 }
 
 // Upload returns the XMLHttpRequestUpload object associated with the
 // request. It can be used to register events for tracking the
 // progress of uploads.
 func (r *Request) Upload() *Upload {
-	o := r.Get("upload")
-	return &Upload{o, util.EventTarget{Object: o}}
+	if r.upload == nil {
+		o := r.Get("upload")
+		t := util.EventTarget{Object: o}
+		r.upload = &Upload{Object: o, EventTarget: t, listeners: &listenerSet{target: t}}
+	}
+	return r.upload
 }
 
-// ErrFailure is the error returned by Send when it failed for a
-// reason other than abortion or timeouts.
+// ErrFailure is wrapped by the errors returned by Send when it failed
+// for a reason other than abortion or timeouts, namely ErrNetwork and
+// ErrCORSBlocked.
 //
-// The specific reason for the error is unknown because the XHR API
-// does not provide us with any information. One common reason is
-// network failure.
+// The specific reason for the error is not exposed by the XHR API, so
+// the distinction between these errors is a best effort guess.
+//
+// Send used to return ErrFailure itself. Since it is now wrapped, checks
+// such as err == xhr.ErrFailure no longer match and must be replaced with
+// errors.Is(err, xhr.ErrFailure).
 var ErrFailure = errors.New("send failed")
 
+// ErrTimeout is wrapped by the *TimeoutError returned by Send when the
+// request's Timeout elapsed before the context's deadline.
+var ErrTimeout = errors.New("request timed out")
+
+// ErrHeadersTimeout is wrapped by the *TimeoutError returned by Send when
+// the request's HeadersTimeout elapsed. It wraps ErrTimeout.
+var ErrHeadersTimeout = fmt.Errorf("waiting for headers: %w", ErrTimeout)
+
+// ErrResponseTooLarge is the error returned by Send when the response
+// exceeded the request's MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response too large")
+
+// ErrAborted is the error returned by Send when the request was aborted
+// with Abort, or by the browser, for example when navigating away.
+var ErrAborted = errors.New("request aborted")
+
+// StrictErrors is the default of Request.StrictErrors. Applications that
+// can't afford to crash, such as long-running SPAs, can set it once
+// during initialization.
+var StrictErrors = false
+
 // NewRequest creates a new XMLHttpRequest object, which may be used
 // for a single request.
 func NewRequest(method, url string) *Request {
-	o := js.Global.Get("XMLHttpRequest").New()
-	r := &Request{Object: o, EventTarget: util.EventTarget{Object: o}}
-	r.Call("open", method, url, true)
+	r := &Request{header: http.Header{}, StrictErrors: StrictErrors}
+	r.init()
+	r.openErr = r.open(method, url, nil)
 	return r
 }
 
+// NewLazyRequest creates a new XMLHttpRequest object like NewRequest,
+// but doesn't open it. Request headers are buffered until the request is
+// opened, either explicitly with Open or implicitly by Send using method
+// and url.
+func NewLazyRequest(method, url string) *Request {
+	r := &Request{header: http.Header{}, method: method, url: url, StrictErrors: StrictErrors}
+	r.init()
+	return r
+}
+
+// init creates the underlying XMLHttpRequest.
+func (r *Request) init() {
+	o := js.Global.Get("XMLHttpRequest").New()
+	t := util.EventTarget{Object: o}
+	r.Object, r.EventTarget = o, t
+	r.listeners = &listenerSet{target: t}
+	r.opened = false
+}
+
+// OpenOptions are the options accepted by Open.
+type OpenOptions struct {
+	// Sync makes Send perform a synchronous request. Browsers only
+	// support synchronous requests in workers, or without a timeout and
+	// ResponseType in windows.
+	Sync bool
+
+	// User and Password are the credentials used for authentication.
+	User     string
+	Password string
+}
+
+// Open opens the request with method and url, applying any buffered
+// request headers. opts may be nil.
+//
+// Open must not be called once the request has been sent. Use Reset
+// instead. Errors are only returned if StrictErrors is set, and Open
+// panics otherwise.
+func (r *Request) Open(method, url string, opts *OpenOptions) error {
+	if r.alreadySent {
+		if r.StrictErrors {
+			return ErrAlreadySent
+		}
+		panic("must not open a Request that has been sent")
+	}
+	r.openErr = r.open(method, url, opts)
+	return r.openErr
+}
+
+// open opens the underlying XMLHttpRequest.
+func (r *Request) open(method, url string, opts *OpenOptions) error {
+	if opts == nil {
+		opts = &OpenOptions{}
+	}
+
+	args := []interface{}{method, url, !opts.Sync}
+	if opts.User != "" || opts.Password != "" {
+		args = append(args, opts.User, opts.Password)
+	}
+	if err := r.call("open", args...); err != nil {
+		return err
+	}
+
+	r.method, r.url = method, url
+	r.openOpts = opts
+	r.opened = true
+	return r.writeHeaders(r.header)
+}
+
+// call calls the named method of the XMLHttpRequest. If StrictErrors is
+// set, JavaScript exceptions are returned as errors rather than panics.
+func (r *Request) call(name string, args ...interface{}) error {
+	if !r.StrictErrors {
+		r.Call(name, args...)
+		return nil
+	}
+	return catch(func() {
+		r.Call(name, args...)
+	})
+}
+
+// record records the error of a call made by a method without an error
+// result, so that Send can return it.
+func (r *Request) record(err error) {
+	if err != nil && r.callErr == nil {
+		r.callErr = err
+	}
+}
+
+// Reset prepares the request to be sent again, to url using method. A
+// fresh XMLHttpRequest is created under the hood, so that request
+// templates can be reused. The request headers, ResponseType,
+// WithCredentials, Timeout, PoolBuffers and the options passed to Open
+// carry over. Listeners are not carried over.
+//
+// A request that is still in flight is aborted first.
+func (r *Request) Reset(method, url string) {
+	if r.alreadySent {
+		r.Abort()
+	}
+	r.RemoveAllListeners()
+
+	responseType, withCredentials := r.ResponseType, r.WithCredentials
+	r.init()
+	r.openErr = r.open(method, url, r.openOpts)
+	r.ResponseType = responseType
+	r.WithCredentials = withCredentials
+
+	r.alreadySent = false
+	r.abortErr = nil
+	r.callErr = nil
+	r.parsedJSON = nil
+	r.bodyView = nil
+	r.upload = nil
+}
+
+// Method returns the method the request was opened with.
+func (r *Request) Method() string {
+	return r.method
+}
+
+// URL returns the url the request was opened with.
+func (r *Request) URL() string {
+	return r.url
+}
+
 // ResponseHeaders returns all response headers.
 func (r *Request) ResponseHeaders() string {
 	return r.Call("getAllResponseHeaders").String()
@@ -149,14 +388,50 @@ func (r *Request) ResponseHeader(name string) string {
 
 // OverrideMimeType overrides the MIME type returned by the server.
 func (r *Request) OverrideMimeType(mimetype string) {
-	r.Call("overrideMimeType", mimetype)
+	r.record(r.call("overrideMimeType", mimetype))
 }
 
-// ResponseBytes returns the ResponseText as a slice of bytes.
+// ResponseBytes returns the response body as a slice of bytes.
+//
+// When ResponseType is ArrayBuffer, the bytes are taken directly from the
+// ArrayBuffer without a round-trip through a string, which roughly halves
+// memory usage for large downloads. Otherwise the ResponseText is
+// returned as a slice of bytes.
 func (r *Request) ResponseBytes() []byte {
+	if r.ResponseType == ArrayBuffer {
+		return r.ResponseArrayBufferBytes(true)
+	}
 	return []byte(r.ResponseText)
 }
 
+// ResponseArrayBufferBytes returns the ArrayBuffer response as a slice
+// of bytes. The request's ResponseType must be ArrayBuffer.
+//
+// The Uint8Array view over the ArrayBuffer is created once and reused by
+// later calls. If share is true, the returned slice shares its memory
+// with the ArrayBuffer, which avoids copying multi-megabyte downloads,
+// but modifications are visible to all users of the response. Otherwise
+// a copy is returned.
+func (r *Request) ResponseArrayBufferBytes(share bool) []byte {
+	if r.ResponseType != ArrayBuffer || r.Response == nil {
+		return nil
+	}
+	if r.bodyView == nil {
+		r.bodyView = js.Global.Get("Uint8Array").New(r.Response).Interface().([]byte)
+	}
+	if share {
+		return r.bodyView
+	}
+	return append([]byte(nil), r.bodyView...)
+}
+
+// BodyReader returns a reader over the response body, as returned by
+// ResponseBytes, so that it can be passed to consumers such as
+// json.Decoder, csv.Reader or archive/zip. Closing it does nothing.
+func (r *Request) BodyReader() io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(r.ResponseBytes()))
+}
+
 // IsStatus2xx returns true if the request returned a 2xx status code.
 func (r *Request) IsStatus2xx() bool {
 	if r.Status < 200 || r.Status > 299 {
@@ -165,6 +440,14 @@ func (r *Request) IsStatus2xx() bool {
 	return true
 }
 
+// IsStatus3xx returns true if the request returned a 3xx status code.
+func (r *Request) IsStatus3xx() bool {
+	if r.Status < 300 || r.Status > 399 {
+		return false
+	}
+	return true
+}
+
 // IsStatus4xx returns true if the request returned a 4xx status code.
 func (r *Request) IsStatus4xx() bool {
 	if r.Status < 400 || r.Status > 499 {
@@ -181,64 +464,263 @@ func (r *Request) IsStatus5xx() bool {
 	return true
 }
 
+// StatusRange is an inclusive range of status codes.
+type StatusRange struct {
+	Min, Max int
+}
+
+// IsStatusIn returns true if the request returned a status code in one
+// of ranges.
+func (r *Request) IsStatusIn(ranges ...StatusRange) bool {
+	for _, sr := range ranges {
+		if r.Status >= sr.Min && r.Status <= sr.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSuccess returns true if the request returned a status code that
+// SuccessPredicate considers successful, or a 2xx status code if
+// SuccessPredicate is nil.
+func (r *Request) IsSuccess() bool {
+	if r.SuccessPredicate != nil {
+		return r.SuccessPredicate(r.Status)
+	}
+	return r.IsStatus2xx()
+}
+
 // Send sends the request that was prepared with Open. The data
 // argument is optional and can either be a string or []byte payload,
 // or a *js.Object containing an ArrayBufferView, Blob, Document or
 // Formdata.
 //
+// An io.Reader is read in chunks into a Blob, so that large generated
+// payloads don't have to be built as a single []byte. See StreamUpload
+// for sending them without buffering. Other Go values are encoded with their Marshal method if they
+// implement BodyMarshaler, or else with the encoder registered for the
+// request's "Content-Type". Values are encoded as JSON if no
+// "Content-Type" has been set. See RegisterEncoder.
+//
 // Send will block until a response was received or an error occured.
+// It panics if the request has already been sent, unless StrictErrors is
+// set, in which case it returns ErrAlreadySent.
 //
 // Only errors of the network layer are treated as errors. HTTP status
-// codes 4xx and 5xx are not treated as errors. In order to check
-// status codes, use the Request's Status field.
+// codes 4xx and 5xx are not treated as errors, unless FailOnError is
+// set. In order to check status codes, use the Request's Status field.
+//
+// ctx is a standard library context.Context, so the package composes with
+// errgroup and other context-aware libraries. Contexts created with the
+// react fork of package context remain accepted since they implement the
+// same interface.
 func (r *Request) Send(ctx context.Context, data interface{}) error {
 
 	if r.alreadySent {
+		if r.StrictErrors {
+			return ErrAlreadySent
+		}
 		panic("must not use a Request for multiple requests")
 	}
+	r.alreadySent = true
 
+	return r.send(ctx, data)
+}
+
+// send performs the request. The caller must have set alreadySent.
+func (r *Request) send(ctx context.Context, data interface{}) error {
+	if !r.opened && r.openErr == nil {
+		r.openErr = r.open(r.method, r.url, nil)
+	}
+	if r.openErr != nil {
+		return r.openErr
+	}
+	if r.callErr != nil {
+		return r.callErr
+	}
+
+	var err error
+	if data, err = r.encodeBody(data); err != nil {
+		return err
+	}
+
+	// The XMLHttpRequest timeout is the smaller of Timeout and the time
+	// left until the ctx deadline. ownTimeout records which one applies.
+	timeout := r.Timeout
+	ownTimeout := timeout > 0
 	if dt, ok := ctx.Deadline(); ok {
-		diff := time.Until(dt) / time.Millisecond
-		if diff != 0 {
-			r.Set("timeout", diff)
+		if diff := time.Until(dt); timeout <= 0 || diff < timeout {
+			timeout = diff
+			ownTimeout = false
 		}
 	}
+	if ms := timeout / time.Millisecond; ms > 0 && !r.openOpts.Sync {
+		r.Set("timeout", ms)
+	}
 
-	errChan := make(chan error)
-	returnedChan := make(chan struct{}) // Used to indicate that this function has returned
+	if r.abortErr != nil {
+		return r.abortErr
+	}
 
+	// The listeners below are detached once send returns, so that the
+	// XMLHttpRequest doesn't retain their closures.
+	internal := &listenerSet{target: r.EventTarget}
+	r.sendListeners = internal
 	defer func() {
-		r.alreadySent = true
-		returnedChan <- struct{}{}
+		internal.removeAll()
+		r.sendListeners = nil
 	}()
 
-	go func() {
-		select {
-		case <-ctx.Done():
-			r.Call("abort")
-			errChan <- ctx.Err()
-		case <-returnedChan:
-		}
-	}()
+	errChan := make(chan error, 1) // Buffered so that the listener never blocks
+	timedOut := false              // Indicate that the XMLHttpRequest timed out
+	abortEvent := false            // Indicate that the XMLHttpRequest was aborted by other means than Abort
 
-	r.AddEventListener("load", false, func(*js.Object) {
-		go func() { errChan <- nil }()
+	internal.add("timeout", func(*js.Object) {
+		timedOut = true
 	})
-	r.AddEventListener("error", false, func(*js.Object) {
-		go func() { errChan <- ErrFailure }()
+
+	// Listening to upload events forces a CORS preflight, so the bytes
+	// sent are only tracked if the caller already listens to them.
+	var uploaded int64
+	if r.upload != nil {
+		up := &listenerSet{target: r.upload.EventTarget}
+		defer up.removeAll()
+		up.add("progress", func(e *js.Object) {
+			uploaded = e.Get("loaded").Int64()
+			r.upload.sent = uploaded
+		})
+	}
+	internal.add("abort", func(*js.Object) {
+		abortEvent = true
 	})
-	r.AddEventListener("timeout", false, func(*js.Object) {
-		go func() { errChan <- context.DeadlineExceeded }()
+
+	// loadend fires exactly once after load, error, abort or timeout, so
+	// it is the single place where the outcome is determined.
+	start := time.Now()
+	internal.add("loadend", func(e *js.Object) {
+		r.duration = time.Since(start)
+
+		var err error
+		switch {
+		case r.abortErr != nil:
+			err = r.abortErr
+		case r.Status != 0:
+			err = r.statusErr()
+		case timedOut && ownTimeout:
+			err = ErrTimeout
+		case timedOut:
+			err = context.DeadlineExceeded
+		case abortEvent:
+			err = ErrAborted
+		default:
+			err = r.classifyFailure()
+		}
+
+		if err == context.DeadlineExceeded || errors.Is(err, ErrTimeout) {
+			err = &TimeoutError{
+				Err:      err,
+				Elapsed:  r.duration,
+				Loaded:   e.Get("loaded").Int64(),
+				Uploaded: uploaded,
+			}
+		}
+		errChan <- err
 	})
 
-	r.Call("send", data)
+	if max := r.MaxResponseBytes; max > 0 {
+		internal.add("progress", func(e *js.Object) {
+			loaded := e.Get("loaded").Int64()
+			total := e.Get("total").Int64()
+			if loaded > max || (e.Get("lengthComputable").Bool() && total > max) {
+				if r.abortErr == nil {
+					r.abortErr = ErrResponseTooLarge
+					r.Call("abort")
+				}
+			}
+		})
+	}
+
+	if r.HeadersTimeout > 0 && !r.openOpts.Sync {
+		t := time.AfterFunc(r.HeadersTimeout, func() {
+			if r.ReadyState < HeadersReceived && r.abortErr == nil {
+				r.abortErr = ErrHeadersTimeout
+				r.Call("abort")
+			}
+		})
+		defer t.Stop()
+	}
+
+	if err := r.call("send", data); err != nil {
+		return err
+	}
 
-	return <-errChan
+	select {
+	case err = <-errChan:
+	case <-ctx.Done():
+		// abort dispatches loadend synchronously. If the request has
+		// already completed, abort does nothing and errChan already
+		// holds the outcome.
+		if r.abortErr == nil {
+			r.abortErr = ctx.Err()
+		}
+		r.Call("abort")
+		err = <-errChan
+	}
+
+	if err == nil && r.Decompress != "" {
+		err = r.decompress()
+	}
+	if err == nil && r.Checksum != nil {
+		err = r.verifyChecksum()
+	}
+	return err
+}
+
+// Abort aborts the request. A pending Send returns ErrAborted, as does
+// a later one. Listeners registered with Listen and the On* methods are
+// notified of the abort and then detached.
+//
+// Abort does nothing if the request has already completed.
+func (r *Request) Abort() {
+	if r.ReadyState == Done || r.abortErr != nil {
+		return
+	}
+	r.abortErr = ErrAborted
+	r.Call("abort")
+	r.RemoveAllListeners()
+}
+
+// AbortUpload aborts the request like Abort and returns the number of
+// body bytes sent as of the last upload progress event, so that the
+// upload can later be resumed from that offset, for example with
+// ChunkedUpload.SetOffset. The bytes sent are only tracked if Upload was
+// called before the request was sent, since listening to upload events
+// forces a CORS preflight.
+func (r *Request) AbortUpload() (sent int64) {
+	r.Abort()
+	return r.upload.BytesSent()
 }
 
 // SetRequestHeader sets a header of the request.
 func (r *Request) SetRequestHeader(header, value string) {
-	r.Call("setRequestHeader", header, value)
+	r.header.Add(header, value)
+	if !r.opened {
+		return
+	}
+	r.record(r.call("setRequestHeader", header, value))
+}
+
+// FailOnError is the value of Request.FailOnError used by the
+// package-level Send, SendRaw, Do, Get and Post functions.
+var FailOnError = false
+
+// statusErr returns the error for the status code of a completed
+// request.
+func (r *Request) statusErr() error {
+	if r.FailOnError && !r.IsSuccess() {
+		return NewStatusError(r)
+	}
+	return nil
 }
 
 // Send constructs a new Request and sends it. The response, if any,
@@ -248,14 +730,34 @@ func (r *Request) SetRequestHeader(header, value string) {
 // types other than []byte, construct a Request yourself.
 //
 // Only errors of the network layer are treated as errors. HTTP status
-// codes 4xx and 5xx are not treated as errors. In order to check
-// status codes, use NewRequest instead.
+// codes 4xx and 5xx are not treated as errors, unless FailOnError is
+// set. In order to check status codes, use NewRequest instead.
 func Send(ctx context.Context, method, url string, data []byte) ([]byte, error) {
 	xhr := NewRequest(method, url)
 	xhr.ResponseType = ArrayBuffer
+	xhr.FailOnError = FailOnError
+	err := xhr.Send(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return xhr.ResponseBytes(), nil
+}
+
+// SendRaw constructs a new Request and sends it. The response is
+// returned as a JavaScript object of the given responseType, typically
+// ArrayBuffer or Blob, and is never copied into Go memory. This suits
+// flows that hand the data straight to another browser API, such as
+// object URLs, IndexedDB or postMessage with transfer.
+//
+// Like Send, only errors of the network layer are treated as errors,
+// unless FailOnError is set.
+func SendRaw(ctx context.Context, method, url, responseType string, data interface{}) (*js.Object, error) {
+	xhr := NewRequest(method, url)
+	xhr.ResponseType = responseType
+	xhr.FailOnError = FailOnError
 	err := xhr.Send(ctx, data)
 	if err != nil {
 		return nil, err
 	}
-	return js.Global.Get("Uint8Array").New(xhr.Response).Interface().([]byte), nil
+	return xhr.Response, nil
 }
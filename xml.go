@@ -0,0 +1,24 @@
+package xhr
+
+import (
+	"encoding/xml"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+// XML decodes the XML response into v using encoding/xml, so that SOAP
+// and other XML APIs can be consumed without walking the DOM.
+//
+// When ResponseType is Document, the parsed document is serialized back
+// into text first. Otherwise the response text is used.
+func (r *Request) XML(v interface{}) error {
+	if r.ResponseType == Document {
+		doc, err := r.document()
+		if err != nil {
+			return err
+		}
+		text := js.Global.Get("XMLSerializer").New().Call("serializeToString", doc).String()
+		return xml.Unmarshal([]byte(text), v)
+	}
+	return xml.Unmarshal(r.ResponseBytes(), v)
+}